@@ -1,15 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 
 	"github.com/kevholditch/tls/internal/cmd"
 )
 
+// exitInterrupted is the conventional exit code after SIGINT, 128 + 2.
+const exitInterrupted = 130
+
 func main() {
-	err := cmd.Run(os.Stdout, os.Stderr, os.Args[1:])
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := cmd.RunContext(ctx, os.Stdin, os.Stdout, os.Stderr, os.Args[1:])
 	if err != nil {
+		var exitErr *cmd.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+		if errors.Is(err, context.Canceled) {
+			os.Exit(exitInterrupted)
+		}
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kevholditch/tls/internal/tls"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes returned by the check command. The first four follow the Nagios
+// plugin convention, the last two are specific to this tool.
+const (
+	ExitOK          = 0
+	ExitWarning     = 1
+	ExitCritical    = 2
+	ExitUnknown     = 3
+	ExitExpired     = 4
+	ExitNotYetValid = 5
+)
+
+// ExitError asks main to exit with Code. Any output has already been written.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+type checkStatus struct {
+	name     string
+	exitCode int
+}
+
+var (
+	statusOK          = checkStatus{name: "OK", exitCode: ExitOK}
+	statusWarning     = checkStatus{name: "WARNING", exitCode: ExitWarning}
+	statusCritical    = checkStatus{name: "CRITICAL", exitCode: ExitCritical}
+	statusNotYetValid = checkStatus{name: "NOT YET VALID", exitCode: ExitNotYetValid}
+	statusExpired     = checkStatus{name: "EXPIRED", exitCode: ExitExpired}
+)
+
+type certCheck struct {
+	cert   *x509.Certificate
+	status checkStatus
+	detail string
+}
+
+func NewCheckCmd(stdIn io.Reader, stdOut, stdErr io.Writer) *cobra.Command {
+	var mode string
+	var server serverFlags
+	var warn string
+	var crit string
+
+	c := &cobra.Command{
+		Use:   "check <target>",
+		Short: "Check a certificate's expiry against thresholds",
+		Long: `Check the expiry of the leaf certificate from a remote TLS endpoint or a
+local file and exit with a status code, for use in monitoring and CI.
+
+Target is interpreted exactly as for the read command.
+
+Thresholds accept a number of days (e.g. 30d) or a Go duration (e.g. 12h).
+
+Only the leaf decides the status, as servers often send extra certificates,
+such as an expired cross-signed root, that clients never use. When there is
+more than one certificate each is also listed on its own line.
+
+Exit codes:
+  0 - OK, the leaf is valid for longer than --warn
+  1 - WARNING, the leaf expires within --warn
+  2 - CRITICAL, the leaf expires within --crit
+  3 - UNKNOWN, the arguments are invalid or the certificates could not be read
+  4 - EXPIRED, the leaf has expired
+  5 - NOT YET VALID, the leaf's validity period has not started`,
+		// Monitoring reads any exit code but 3 as a verdict on the
+		// certificate, so argument errors must report UNKNOWN too.
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return unknown(stdOut, err)
+			}
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			warnAfter, err := parseThreshold(warn)
+			if err != nil {
+				return unknown(stdOut, err)
+			}
+			critAfter, err := parseThreshold(crit)
+			if err != nil {
+				return unknown(stdOut, err)
+			}
+			if critAfter > warnAfter {
+				return unknown(stdOut, fmt.Errorf("--crit (%s) must not be greater than --warn (%s)", crit, warn))
+			}
+
+			parsedMode, err := tls.ParseMode(mode)
+			if err != nil {
+				return unknown(stdOut, err)
+			}
+
+			serverOpts, err := server.options()
+			if err != nil {
+				return unknown(stdOut, err)
+			}
+
+			result, err := tls.Read(cmd.Context(), args[0], parsedMode, stdIn, serverOpts)
+			if err != nil {
+				return unknown(stdOut, err)
+			}
+
+			return printChecks(stdOut, checkCerts(result.Certificates, time.Now(), warnAfter, critAfter))
+		},
+	}
+
+	c.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return unknown(stdOut, err)
+	})
+
+	c.Flags().StringVar(&mode, "mode", "auto", "input mode: auto, file, or server")
+	server.register(c.Flags())
+	c.Flags().StringVar(&warn, "warn", "30d", "warn when the leaf certificate expires within this time")
+	c.Flags().StringVar(&crit, "crit", "7d", "critical when the leaf certificate expires within this time")
+
+	return c
+}
+
+func checkCerts(certs []*x509.Certificate, now time.Time, warnAfter, critAfter time.Duration) []certCheck {
+	checks := make([]certCheck, 0, len(certs))
+
+	for _, cert := range certs {
+		validity := tls.CheckValidity(cert, now)
+
+		check := certCheck{
+			cert:   cert,
+			detail: fmt.Sprintf("expires in %s (%s)", tls.HumanDuration(validity.Remaining), cert.NotAfter.Format(time.RFC3339)),
+		}
+		switch {
+		case validity.Status == tls.StatusNotYetValid:
+			check.status = statusNotYetValid
+			check.detail = fmt.Sprintf("valid from %s", cert.NotBefore.Format(time.RFC3339))
+		case validity.Status == tls.StatusExpired:
+			check.status = statusExpired
+			check.detail = fmt.Sprintf("expired %s ago (%s)", tls.HumanDuration(validity.Remaining), cert.NotAfter.Format(time.RFC3339))
+		case validity.Remaining <= critAfter:
+			check.status = statusCritical
+		case validity.Remaining <= warnAfter:
+			check.status = statusWarning
+		default:
+			check.status = statusOK
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// unknown reports err the way a Nagios plugin reports that it could not
+// check anything, and returns the UNKNOWN exit code.
+func unknown(w io.Writer, err error) error {
+	if _, err := fmt.Fprintf(w, "UNKNOWN - %s\n", err); err != nil {
+		return err
+	}
+	return &ExitError{Code: ExitUnknown}
+}
+
+// printChecks writes a summary line for the leaf, followed by a line per
+// certificate when there is more than one, and returns the leaf's exit code.
+func printChecks(w io.Writer, checks []certCheck) error {
+	leaf := checks[0]
+
+	if _, err := fmt.Fprintf(w, "%s - %s: %s\n", leaf.status.name, leaf.cert.Subject.CommonName, leaf.detail); err != nil {
+		return err
+	}
+
+	if len(checks) > 1 {
+		for i, check := range checks {
+			if _, err := fmt.Fprintf(w, "[%d] %s - %s: %s\n", i+1, check.status.name, check.cert.Subject.CommonName, check.detail); err != nil {
+				return err
+			}
+		}
+	}
+
+	if leaf.status.exitCode == ExitOK {
+		return nil
+	}
+	return &ExitError{Code: leaf.status.exitCode}
+}
+
+// parseThreshold parses a number of days such as "30d", or any duration
+// understood by time.ParseDuration.
+func parseThreshold(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid threshold: %s", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid threshold: %s", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runCheckCommand runs the check command and returns the output and exit code
+func runCheckCommand(t *testing.T, checkArgs ...string) (string, int) {
+	t.Helper()
+
+	var out, errOut bytes.Buffer
+
+	args := append([]string{"check"}, checkArgs...)
+	err := Run(&bytes.Buffer{}, &out, &errOut, args)
+	if err == nil {
+		return out.String(), ExitOK
+	}
+
+	exitErr, ok := err.(*ExitError)
+	if !ok {
+		t.Fatalf("failed to check certificate: %v", err)
+	}
+	return out.String(), exitErr.Code
+}
+
+func TestCheckCommandOK(t *testing.T) {
+	filePath := writePEMFile(t, buildExampleCertThatExpiresIn(tenDays))
+	output, code := runCheckCommand(t, filePath, "--warn", "5d", "--crit", "2d")
+
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, output, "OK - example.com: expires in 9 days 23 hours")
+}
+
+func TestCheckCommandWarning(t *testing.T) {
+	filePath := writePEMFile(t, buildExampleCertThatExpiresIn(tenDays))
+	output, code := runCheckCommand(t, filePath)
+
+	assert.Equal(t, ExitWarning, code)
+	assert.Contains(t, output, "WARNING - example.com: expires in 9 days 23 hours")
+}
+
+func TestCheckCommandCritical(t *testing.T) {
+	exampleCert := buildExampleCertThatExpiresIn(day)
+	server := setupTestServer(t, exampleCert)
+	output, code := runCheckCommand(t, server.GetAddress())
+
+	assert.Equal(t, ExitCritical, code)
+	assert.Contains(t, output, "CRITICAL - example.com: expires in 23 hours")
+}
+
+func TestCheckCommandExpired(t *testing.T) {
+	exampleCert := DefaultCertBuilder().
+		WithValidity(time.Now().Add(-tenDays), time.Now().Add(-2*day)).
+		BuildCert()
+	output, code := runCheckCommand(t, writePEMFile(t, exampleCert))
+
+	assert.Equal(t, ExitExpired, code)
+	assert.Contains(t, output, "EXPIRED - example.com: expired 2 days 0 hours ago")
+}
+
+func TestCheckCommandNotYetValid(t *testing.T) {
+	exampleCert := DefaultCertBuilder().
+		WithValidity(time.Now().Add(2*day), time.Now().Add(tenDays)).
+		BuildCert()
+	output, code := runCheckCommand(t, writePEMFile(t, exampleCert))
+
+	assert.Equal(t, ExitNotYetValid, code)
+	assert.Contains(t, output, "NOT YET VALID - example.com: valid from")
+}
+
+func TestCheckCommandLeafDecidesStatus(t *testing.T) {
+	valid := DefaultCertBuilder().Build()
+	expired := DefaultCertBuilder().
+		WithCommonName("expired.example.com").
+		WithValidity(time.Now().Add(-tenDays), time.Now().Add(-2*day)).
+		Build()
+
+	var bundle []byte
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: valid.Certificate[0]})...)
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: expired.Certificate[0]})...)
+
+	output, code := runCheckCommand(t, writeFile(t, bundle), "--warn", "5d", "--crit", "2d")
+
+	assert.Equal(t, ExitOK, code)
+	assert.True(t, strings.HasPrefix(output, "OK - example.com: expires in 9 days 23 hours"))
+	assert.Contains(t, output, "[1] OK - example.com: expires in 9 days 23 hours")
+	assert.Contains(t, output, "[2] EXPIRED - expired.example.com: expired 2 days 0 hours ago")
+}
+
+func TestCheckCommandExpiredLeaf(t *testing.T) {
+	expired := DefaultCertBuilder().
+		WithValidity(time.Now().Add(-tenDays), time.Now().Add(-2*day)).
+		Build()
+	valid := DefaultCertBuilder().WithCommonName("valid.example.com").Build()
+
+	var bundle []byte
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: expired.Certificate[0]})...)
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: valid.Certificate[0]})...)
+
+	output, code := runCheckCommand(t, writeFile(t, bundle), "--warn", "5d", "--crit", "2d")
+
+	assert.Equal(t, ExitExpired, code)
+	assert.True(t, strings.HasPrefix(output, "EXPIRED - example.com: expired 2 days 0 hours ago"))
+}
+
+func TestCheckCommandUnreadableTarget(t *testing.T) {
+	output, code := runCheckCommand(t, "./does-not-exist.pem")
+
+	assert.Equal(t, ExitUnknown, code)
+	assert.Contains(t, output, "UNKNOWN - open ./does-not-exist.pem")
+}
+
+func TestCheckCommandInvalidThresholds(t *testing.T) {
+	output, code := runCheckCommand(t, "--warn", "7d", "--crit", "30d", "example.com")
+
+	assert.Equal(t, ExitUnknown, code)
+	assert.Equal(t, "UNKNOWN - --crit (30d) must not be greater than --warn (7d)\n", output)
+}
+
+func TestCheckCommandMalformedThreshold(t *testing.T) {
+	output, code := runCheckCommand(t, "--warn", "soon", "example.com")
+
+	assert.Equal(t, ExitUnknown, code)
+	assert.Equal(t, "UNKNOWN - invalid threshold: soon\n", output)
+}
+
+func TestCheckCommandUnknownFlag(t *testing.T) {
+	output, code := runCheckCommand(t, "--bogus", "x")
+
+	assert.Equal(t, ExitUnknown, code)
+	assert.Equal(t, "UNKNOWN - unknown flag: --bogus\n", output)
+}
+
+func TestCheckCommandWrongNumberOfArguments(t *testing.T) {
+	output, code := runCheckCommand(t)
+
+	assert.Equal(t, ExitUnknown, code)
+	assert.Equal(t, "UNKNOWN - accepts 1 arg(s), received 0\n", output)
+
+	output, code = runCheckCommand(t, "a", "b")
+
+	assert.Equal(t, ExitUnknown, code)
+	assert.Equal(t, "UNKNOWN - accepts 1 arg(s), received 2\n", output)
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+// proxyEnv lists the variables that ProxyFromEnvironment reads.
+var proxyEnv = []string{"https_proxy", "HTTPS_PROXY", "all_proxy", "ALL_PROXY", "no_proxy", "NO_PROXY"}
+
+// TestMain stops a proxy set in the developer's environment from reaching
+// the test servers. Tests that need a proxy variable set it with t.Setenv.
+func TestMain(m *testing.M) {
+	for _, name := range proxyEnv {
+		_ = os.Unsetenv(name)
+	}
+
+	os.Exit(m.Run())
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kevholditch/tls/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupProxyServer creates and starts a proxy test server for protocol
+func setupProxyServer(t *testing.T, protocol, username, password string) *testutil.ProxyServer {
+	t.Helper()
+
+	proxy, err := testutil.NewProxyServer(protocol)
+	if err != nil {
+		t.Fatalf("failed to create proxy server: %v", err)
+	}
+	if username != "" {
+		proxy.WithCredentials(username, password)
+	}
+
+	ready := make(chan struct{})
+	go func() {
+		if err := proxy.Start(ready); err != nil {
+			t.Errorf("proxy server error: %v", err)
+		}
+	}()
+
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for proxy server to start")
+	}
+
+	t.Cleanup(func() {
+		if err := proxy.Stop(); err != nil {
+			t.Errorf("failed to stop proxy server: %v", err)
+		}
+	})
+
+	return proxy
+}
+
+func TestReadCommandThroughProxy(t *testing.T) {
+	tests := []struct {
+		protocol string
+		username string
+		password string
+	}{
+		{"http", "", ""},
+		{"http", "user", "secret"},
+		{"socks5", "", ""},
+		{"socks5", "user", "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.protocol+"/"+tt.username, func(t *testing.T) {
+			server := setupTestServer(t, buildExampleCertThatExpiresIn(48*time.Hour))
+			proxy := setupProxyServer(t, tt.protocol, tt.username, tt.password)
+
+			proxyURL := tt.protocol + "://" + proxy.GetAddress()
+			if tt.username != "" {
+				proxyURL = tt.protocol + "://" + tt.username + ":" + tt.password + "@" + proxy.GetAddress()
+			}
+			output := runReadCommand(t, server.GetAddress(), "--proxy", proxyURL)
+
+			assert.Contains(t, output, "Common Name:  example.com")
+			assert.Equal(t, []string{server.GetAddress()}, proxy.Tunnels())
+		})
+	}
+}
+
+func TestReadCommandProxyWrongCredentials(t *testing.T) {
+	tests := []struct {
+		protocol string
+		expected func(target string) string
+	}{
+		{"http", func(target string) string {
+			return "proxy CONNECT to " + target + " failed: 407 Proxy Authentication Required"
+		}},
+		{"socks5", func(string) string { return "SOCKS5 proxy rejected the username and password" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.protocol, func(t *testing.T) {
+			server := setupTestServer(t, buildExampleCertThatExpiresIn(48*time.Hour))
+			proxy := setupProxyServer(t, tt.protocol, "user", "secret")
+
+			var out, errOut bytes.Buffer
+			err := Run(&bytes.Buffer{}, &out, &errOut,
+				[]string{"read", server.GetAddress(), "--proxy", tt.protocol + "://user:wrong@" + proxy.GetAddress()})
+
+			assert.EqualError(t, err, tt.expected(server.GetAddress()))
+		})
+	}
+}
+
+func TestReadCommandProxyFromEnvironment(t *testing.T) {
+	server := setupTestServer(t, buildExampleCertThatExpiresIn(48*time.Hour))
+	proxy := setupProxyServer(t, "socks5", "", "")
+	t.Setenv("ALL_PROXY", proxy.URL())
+
+	// Loopback addresses are never proxied, so name the server something else.
+	_, port, _ := net.SplitHostPort(server.GetAddress())
+	output := runReadCommand(t, "example.com:"+port, "--resolve", "example.com:"+port+":127.0.0.1")
+
+	assert.Contains(t, output, "Common Name:  example.com")
+	assert.Equal(t, []string{server.GetAddress()}, proxy.Tunnels())
+}
+
+func TestReadCommandEnvironmentProxyBypassesLoopback(t *testing.T) {
+	server := setupTestServer(t, buildExampleCertThatExpiresIn(48*time.Hour))
+	proxy := setupProxyServer(t, "http", "", "")
+	t.Setenv("HTTPS_PROXY", proxy.URL())
+
+	output := runReadCommand(t, server.GetAddress())
+
+	assert.Contains(t, output, "Common Name:  example.com")
+	assert.Empty(t, proxy.Tunnels())
+}
+
+func TestReadCommandSOCKS5HLeavesNamesToProxy(t *testing.T) {
+	server := setupTestServer(t, buildExampleCertThatExpiresIn(48*time.Hour))
+	proxy := setupProxyServer(t, "socks5", "", "")
+
+	_, port, _ := net.SplitHostPort(server.GetAddress())
+	output := runReadCommand(t, "localhost:"+port, "--proxy", "socks5h://"+proxy.GetAddress())
+
+	assert.Contains(t, output, "Common Name:  example.com")
+	assert.Equal(t, []string{"localhost:" + port}, proxy.Tunnels())
+}
+
+func TestReadCommandNoProxyBypassesEnvironmentProxy(t *testing.T) {
+	server := setupTestServer(t, buildExampleCertThatExpiresIn(48*time.Hour))
+	proxy := setupProxyServer(t, "http", "", "")
+	t.Setenv("HTTPS_PROXY", proxy.URL())
+	t.Setenv("NO_PROXY", "example.com")
+
+	_, port, _ := net.SplitHostPort(server.GetAddress())
+	output := runReadCommand(t, "example.com:"+port, "--resolve", "example.com:"+port+":127.0.0.1")
+
+	assert.Contains(t, output, "Common Name:  example.com")
+	assert.Empty(t, proxy.Tunnels())
+}
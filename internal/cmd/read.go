@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"io"
 	"time"
 
@@ -9,8 +10,16 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func NewReadCmd(stdOut, stdErr io.Writer) *cobra.Command {
+func NewReadCmd(stdIn io.Reader, stdOut, stdErr io.Writer) *cobra.Command {
 	var mode string
+	var server serverFlags
+	var output string
+	var caFile string
+	var caDir string
+	var serverName string
+	var showConnection bool
+	var fingerprintOnly string
+	var logListFile string
 
 	c := &cobra.Command{
 		Use:   "read <target>",
@@ -19,14 +28,61 @@ func NewReadCmd(stdOut, stdErr io.Writer) *cobra.Command {
 
 Target can be:
   - hostname[:port]         e.g. example.com or example.com:8443
-  - URL                     e.g. https://example.com
+  - IP[:port]               e.g. 10.0.0.1, 2001:db8::1 or [2001:db8::1]:8443
+  - URL                     e.g. https://example.com/path or ldaps://ldap.example.com
   - file path               e.g. ./cert.pem
   - "-" (stdin)             e.g. cat cert.pem | tls read -
 
 Mode controls how target is interpreted:
   auto   - detect host vs file (default)
-  file   - treat target as a file path
-  server - treat target as a remote server`,
+  file   - treat target as a file path, or "-" for stdin
+  server - treat target as a remote server
+
+Output controls the format written to stdout:
+  text   - human readable layout (default)
+  json   - a stable JSON document for scripts, e.g. piped to jq
+
+The chain is verified against the system roots, or only against the
+certificates given by --ca-file and --ca-dir when either is set. Server
+certificates must also be valid for the host that was connected to, or for
+--servername when it is set. Use --servername to check a file against a host.
+
+To test a specific node or an address before a DNS cutover, --connect-to and
+--resolve dial a different address while presenting the target's server name,
+and --sni presents a different server name. --no-sni sends no server name to
+show the server's default certificate.
+
+Servers that upgrade a plaintext connection are read with --starttls smtp,
+imap, pop3, ftp, postgres, mysql, ldap or xmpp. It is picked automatically on
+the well-known ports 25, 587, 143, 110, 21, 5432, 3306, 389 and 5222, or from
+the scheme of a URL such as smtp://mail.example.com:2525. Any other line
+based protocol can use --starttls generic, which sends --starttls-send and
+waits for a reply starting with --starttls-expect.
+
+Connections go through the proxy in HTTPS_PROXY or ALL_PROXY unless the host
+is listed in NO_PROXY, or is localhost or a loopback address. --proxy
+http://[user:pass@]host:port or --proxy socks5://[user:pass@]host:port picks a
+proxy explicitly. socks5:// resolves host names locally, socks5h:// leaves
+them to the proxy.
+
+--timeout limits the whole read (default 30s) and --handshake-timeout the
+STARTTLS exchange and TLS handshake (default 10s). Timeouts say whether the
+DNS lookup, TCP connect or TLS handshake was too slow.
+
+--connection also shows what was negotiated with a server: the protocol
+version, cipher suite, key exchange group, ALPN protocol, OCSP stapling and
+SCTs. JSON output always includes it for servers. No ALPN protocols are
+offered unless they are listed with --alpn, e.g. --alpn h2,http/1.1.
+
+The Certificate Transparency SCTs embedded in the leaf certificate or sent in
+the handshake are shown with their log ID, timestamp and signature algorithm.
+--ct-log-list names the logs from a log list in Chrome's v3 JSON format, e.g.
+https://www.gstatic.com/ct/log_list/v3/log_list.json.
+
+--fingerprint-only prints nothing but one fingerprint per certificate, leaf
+first, for scripts: the base64 SHA-256 SPKI pin used by HPKP, Envoy and
+OkHttp (the default), or --fingerprint-only=sha256 or sha1 for the colon
+separated hex digest of the certificate.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			target := args[0]
@@ -36,16 +92,118 @@ Mode controls how target is interpreted:
 				return err
 			}
 
-			cert, err := tls.Read(target, parsedMode)
+			serverOpts, err := server.options()
+			if err != nil {
+				return err
+			}
+
+			format, err := pretty.ParseFormat(output)
 			if err != nil {
 				return err
 			}
-			return pretty.Print(stdOut, cert, time.Now())
+
+			var fingerprint pretty.Fingerprint
+			if cmd.Flags().Changed("fingerprint-only") {
+				if format == pretty.FormatJSON {
+					return fmt.Errorf("--fingerprint-only cannot be combined with --output json")
+				}
+				if fingerprint, err = pretty.ParseFingerprint(fingerprintOnly); err != nil {
+					return err
+				}
+			}
+
+			roots, err := tls.LoadRoots(caFile, caDir)
+			if err != nil {
+				return err
+			}
+
+			logs, err := tls.LoadLogList(logListFile)
+			if err != nil {
+				return err
+			}
+
+			result, err := tls.Read(cmd.Context(), target, parsedMode, stdIn, serverOpts)
+			if err != nil {
+				return err
+			}
+
+			for _, skipped := range result.Skipped {
+				if _, err := fmt.Fprintf(stdErr, "note: skipped PEM block of type %q\n", skipped); err != nil {
+					return err
+				}
+			}
+
+			if fingerprint != "" {
+				return pretty.PrintFingerprints(stdOut, result.Certificates, fingerprint)
+			}
+
+			now := time.Now()
+			if serverName == "" {
+				serverName = result.ServerName
+			}
+
+			verification := pretty.NewVerification(tls.Verify(result.Certificates, roots, serverName, now))
+			if serverName != "" {
+				verification.Hostname = pretty.NewHostname(tls.MatchHostname(result.Certificates[0], serverName))
+			}
+
+			scts, err := tls.EmbeddedSCTs(result.Certificates[0])
+			if err != nil {
+				if _, err := fmt.Fprintf(stdErr, "note: %s\n", err); err != nil {
+					return err
+				}
+			}
+
+			var conn *pretty.Connection
+			if result.Connection != nil {
+				conn = pretty.NewConnection(result.Address, result.SNI, result.Connection)
+
+				handshakeSCTs, err := tls.HandshakeSCTs(result.Connection)
+				if err != nil {
+					if _, err := fmt.Fprintf(stdErr, "note: %s\n", err); err != nil {
+						return err
+					}
+				}
+				scts = append(scts, handshakeSCTs...)
+			}
+
+			if format == pretty.FormatJSON {
+				doc := pretty.NewDocument(result.Certificates, now)
+				doc.Verification = &verification
+				doc.Connection = conn
+				doc.SCTs = pretty.NewSCTs(scts, logs)
+				return pretty.PrintJSON(stdOut, doc)
+			}
+
+			if err := pretty.PrintChain(stdOut, result.Certificates, now); err != nil {
+				return err
+			}
+			if err := pretty.PrintVerification(stdOut, verification); err != nil {
+				return err
+			}
+			// Not having SCTs only matters for a certificate served to browsers.
+			if len(scts) > 0 || conn != nil {
+				if err := pretty.PrintSCTs(stdOut, pretty.NewSCTs(scts, logs)); err != nil {
+					return err
+				}
+			}
+			if showConnection && conn != nil {
+				return pretty.PrintConnection(stdOut, conn)
+			}
+			return nil
 		},
 	}
 
 	c.Flags().StringVar(&mode, "mode", "auto", "input mode: auto, file, or server")
+	server.register(c.Flags())
+	c.Flags().StringVarP(&output, "output", "o", "text", "output format: text or json")
+	c.Flags().StringVar(&caFile, "ca-file", "", "verify against the CA certificates in this file instead of the system roots")
+	c.Flags().StringVar(&caDir, "ca-dir", "", "verify against the CA certificates in this directory instead of the system roots")
+	c.Flags().StringVar(&serverName, "servername", "", "verify the leaf certificate against this host name instead of the target host")
+	c.Flags().BoolVar(&showConnection, "connection", false, "show the protocol version, cipher suite and other parameters negotiated with a server")
+	c.Flags().StringVar(&fingerprintOnly, "fingerprint-only", "", "print only the fingerprint of each certificate: spki (default), sha256 or sha1")
+	c.Flags().Lookup("fingerprint-only").NoOptDefVal = string(pretty.FingerprintSPKI)
+	c.Flags().StringVar(&logListFile, "ct-log-list", "", "name Certificate Transparency logs from this log list JSON file")
 
 	return c
 }
-
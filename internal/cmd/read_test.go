@@ -4,18 +4,29 @@ import (
 	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"io"
 	"math/big"
+	"net"
+	"net/url"
 	"os"
 	"path"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kevholditch/tls/internal/pretty"
 	"github.com/kevholditch/tls/internal/testutil"
 	"github.com/stretchr/testify/assert"
 )
@@ -53,8 +64,6 @@ func buildExampleCertWithDNSNames(dnsNames ...string) *x509.Certificate {
 }
 
 func writePEMFile(t *testing.T, cert *x509.Certificate) string {
-	certPath := path.Join(os.TempDir(), fmt.Sprintf("cert-%s.pem", uuid.New().String()))
-
 	// Create a properly signed certificate to get Raw bytes
 	priv, err := rsa.GenerateKey(rand.Reader, 2048)
 	assert.NoError(t, err)
@@ -68,22 +77,64 @@ func writePEMFile(t *testing.T, cert *x509.Certificate) string {
 		Bytes: derBytes,
 	}
 
-	err = os.WriteFile(certPath, pem.EncodeToMemory(pemBlock), 0644)
-	assert.NoError(t, err)
+	return writeFile(t, pem.EncodeToMemory(pemBlock))
+}
 
-	return certPath
+// buildChain creates a root CA, an intermediate CA signed by the root and a
+// leaf signed by the intermediate
+func buildChain() (leaf, intermediate, root tls.Certificate) {
+	root = testutil.NewCertBuilder().
+		WithSubject(func() pkix.Name { return pkix.Name{CommonName: "Test Root CA"} }).
+		WithValidityDuration(tenDays).
+		WithSerialNumber(big.NewInt(1)).
+		WithCA(true).
+		Build()
+
+	intermediate = testutil.NewCertBuilder().
+		WithSubject(func() pkix.Name { return pkix.Name{CommonName: "Test Intermediate CA"} }).
+		WithValidityDuration(tenDays).
+		WithSerialNumber(big.NewInt(2)).
+		WithCA(true).
+		BuildSignedBy(root)
+
+	leaf = DefaultCertBuilder().WithIPAddresses(net.ParseIP("127.0.0.1")).BuildSignedBy(intermediate)
+
+	return leaf, intermediate, root
 }
 
 // setupTestServer creates and starts a test server with the given certificate
 func setupTestServer(t *testing.T, cert *x509.Certificate) *testutil.TestServer {
 	t.Helper()
 
-	server, err := testutil.NewTestServer(func(b *testutil.TlsConfigBuilder) *tls.Config {
-		return b.WithCerts(testutil.NewCertBuilder().WithCert(cert).Build()).
-			WithMaximumTLSVersion(tls.VersionTLS13).
-			WithMinimumTLSVersion(tls.VersionTLS12).
+	return setupTestServerWithCert(t, testutil.NewCertBuilder().WithCert(cert).Build())
+}
+
+// setupTestServerWithCert creates and starts a test server presenting the given certificate chains,
+// chosen by SNI with the first as the default
+func setupTestServerWithCert(t *testing.T, certs ...tls.Certificate) *testutil.TestServer {
+	t.Helper()
+
+	return setupTestServerWithVersions(t, tls.VersionTLS12, tls.VersionTLS13, certs...)
+}
+
+// setupTestServerWithVersions creates and starts a test server that only accepts protocol versions
+// from minVersion to maxVersion
+func setupTestServerWithVersions(t *testing.T, minVersion, maxVersion uint16, certs ...tls.Certificate) *testutil.TestServer {
+	t.Helper()
+
+	return setupTestServerWithConfig(t, func(b *testutil.TlsConfigBuilder) *tls.Config {
+		return b.WithCerts(certs...).
+			WithMaximumTLSVersion(maxVersion).
+			WithMinimumTLSVersion(minVersion).
 			Build()
 	})
+}
+
+// setupTestServerWithConfig creates and starts a test server with the TLS config from buildTlsConfig
+func setupTestServerWithConfig(t *testing.T, buildTlsConfig func(b *testutil.TlsConfigBuilder) *tls.Config) *testutil.TestServer {
+	t.Helper()
+
+	server, err := testutil.NewTestServer(buildTlsConfig)
 	if err != nil {
 		t.Fatalf("failed to create test server: %v", err)
 	}
@@ -110,19 +161,44 @@ func setupTestServer(t *testing.T, cert *x509.Certificate) *testutil.TestServer
 	return server
 }
 
+// writeFile writes data to a new temporary file and returns its path
+func writeFile(t *testing.T, data []byte) string {
+	certPath := path.Join(os.TempDir(), fmt.Sprintf("cert-%s.pem", uuid.New().String()))
+
+	err := os.WriteFile(certPath, data, 0644)
+	assert.NoError(t, err)
+
+	return certPath
+}
+
 // runReadCommand runs the read command and returns the output
 func runReadCommand(t *testing.T, readArgs ...string) string {
 	t.Helper()
 
+	out, _ := runReadCommandWithStdErr(t, readArgs...)
+	return out
+}
+
+// runReadCommandWithStdErr runs the read command and returns both stdout and stderr
+func runReadCommandWithStdErr(t *testing.T, readArgs ...string) (string, string) {
+	t.Helper()
+
+	return runReadCommandWithStdIn(t, &bytes.Buffer{}, readArgs...)
+}
+
+// runReadCommandWithStdIn runs the read command with the given stdin and returns both stdout and stderr
+func runReadCommandWithStdIn(t *testing.T, stdIn io.Reader, readArgs ...string) (string, string) {
+	t.Helper()
+
 	var out, errOut bytes.Buffer
 
 	args := append([]string{"read"}, readArgs...)
-	err := Run(&out, &errOut, args)
+	err := Run(stdIn, &out, &errOut, args)
 	if err != nil {
 		t.Fatalf("failed to read certificate: %v", err)
 	}
 
-	return out.String()
+	return out.String(), errOut.String()
 }
 
 func TestReadCommandServerWithCertExpiringInLessThanOneWeek(t *testing.T) {
@@ -192,3 +268,755 @@ func TestReadCommandPEMFile(t *testing.T) {
                 www.example.com
               ]`)
 }
+
+func TestReadCommandServerShowsFullChain(t *testing.T) {
+	leaf, intermediate, root := buildChain()
+	server := setupTestServerWithCert(t, testutil.Chain(leaf, intermediate, root))
+	output := runReadCommand(t, server.GetAddress())
+
+	assert.Contains(t, output, "Certificate 1 of 3 (leaf)")
+	assert.Contains(t, output, "Certificate 2 of 3 (intermediate)")
+	assert.Contains(t, output, "Certificate 3 of 3 (root)")
+	assert.Contains(t, output, "Issuer:       CN=Test Intermediate CA")
+	assert.Contains(t, output, `Chain:
+  [1] example.com (leaf)
+      issued by [2] Test Intermediate CA
+  [2] Test Intermediate CA (intermediate)
+      issued by [3] Test Root CA
+  [3] Test Root CA (root)
+      self-signed`)
+}
+
+func TestReadCommandServerMissingIntermediate(t *testing.T) {
+	leaf, _, _ := buildChain()
+	server := setupTestServerWithCert(t, leaf)
+	output := runReadCommand(t, server.GetAddress())
+
+	assert.Contains(t, output, "Certificate 1 of 1 (leaf)")
+	assert.Contains(t, output, `Chain:
+  [1] example.com (leaf)
+      issued by Test Intermediate CA (not presented)`)
+}
+
+func TestReadCommandSelfSignedServerCertIsLeaf(t *testing.T) {
+	path := writePEMFile(t, buildExampleCertWithDNSNames("example.com"))
+
+	output := runReadCommand(t, path)
+	assert.Contains(t, output, "Certificate 1 of 1 (leaf)")
+	assert.Contains(t, output, "  [1] example.com (leaf)\n      self-signed")
+
+	var doc pretty.Document
+	assert.NoError(t, json.Unmarshal([]byte(runReadCommand(t, path, "-o", "json")), &doc))
+	assert.Equal(t, "leaf", doc.Certificates[0].Position)
+}
+
+func TestReadCommandPEMBundleFile(t *testing.T) {
+	leaf, intermediate, _ := buildChain()
+
+	var bundle []byte
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Certificate[0]})...)
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: []byte{1, 2, 3}})...)
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: intermediate.Certificate[0]})...)
+
+	output, errOutput := runReadCommandWithStdErr(t, writeFile(t, bundle))
+
+	assert.Contains(t, output, "Certificate 1 of 2 (leaf)")
+	assert.Contains(t, output, "Certificate 2 of 2 (intermediate)")
+	assert.Contains(t, output, "Common Name:  Test Intermediate CA")
+	assert.Contains(t, errOutput, `note: skipped PEM block of type "RSA PRIVATE KEY"`)
+}
+
+func TestReadCommandStdin(t *testing.T) {
+	leaf, intermediate, _ := buildChain()
+
+	var bundle []byte
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Certificate[0]})...)
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: intermediate.Certificate[0]})...)
+
+	output, _ := runReadCommandWithStdIn(t, bytes.NewReader(bundle), "-")
+
+	assert.Contains(t, output, "Certificate 1 of 2 (leaf)")
+	assert.Contains(t, output, "Common Name:  example.com")
+	assert.Contains(t, output, "Common Name:  Test Intermediate CA")
+}
+
+func TestReadCommandDERFile(t *testing.T) {
+	leaf, _, _ := buildChain()
+	output := runReadCommand(t, writeFile(t, leaf.Certificate[0]))
+
+	assert.Contains(t, output, "Certificate 1 of 1 (leaf)")
+	assert.Contains(t, output, "Common Name:  example.com")
+}
+
+func TestReadCommandJSONOutput(t *testing.T) {
+	leaf, intermediate, _ := buildChain()
+	server := setupTestServerWithCert(t, testutil.Chain(leaf, intermediate))
+	output := runReadCommand(t, server.GetAddress(), "--output", "json")
+
+	var doc pretty.Document
+	assert.NoError(t, json.Unmarshal([]byte(output), &doc))
+	assert.Len(t, doc.Certificates, 2)
+
+	first := doc.Certificates[0]
+	assert.Equal(t, 1, first.Index)
+	assert.Equal(t, "leaf", first.Position)
+	assert.Equal(t, "example.com", first.CommonName)
+	assert.Equal(t, "CN=Test Intermediate CA", first.Issuer)
+	assert.Equal(t, "123", first.Serial)
+	assert.Equal(t, []string{}, first.DNSNames)
+	assert.InDelta(t, tenDays.Seconds(), first.SecondsUntilExpiry, 60)
+	assert.Equal(t, pretty.PublicKey{Algorithm: "RSA", Size: 2048, Exponent: 65537}, first.PublicKey)
+	sum := sha256.Sum256(leaf.Certificate[0])
+	assert.Equal(t, strings.ReplaceAll(fmt.Sprintf("% X", sum[:]), " ", ":"), first.Fingerprints.SHA256)
+
+	assert.Equal(t, "intermediate", doc.Certificates[1].Position)
+}
+
+func TestReadCommandInvalidOutput(t *testing.T) {
+	var out, errOut bytes.Buffer
+	err := Run(&bytes.Buffer{}, &out, &errOut, []string{"read", "--output", "yaml", "example.com"})
+
+	assert.EqualError(t, err, "invalid output: yaml (must be text or json)")
+}
+
+func TestReadCommandExpiredCert(t *testing.T) {
+	exampleCert := DefaultCertBuilder().
+		WithValidity(time.Now().Add(-tenDays), time.Now().Add(-3*day-5*time.Hour-time.Minute)).
+		BuildCert()
+	output := runReadCommand(t, writePEMFile(t, exampleCert))
+
+	assert.Contains(t, output, "Expires In:   ❌ Expired 3 days 5 hours ago")
+}
+
+func TestReadCommandNotYetValidCert(t *testing.T) {
+	exampleCert := DefaultCertBuilder().
+		WithValidity(time.Now().Add(2*day+time.Minute), time.Now().Add(tenDays)).
+		BuildCert()
+	output := runReadCommand(t, writePEMFile(t, exampleCert))
+
+	assert.Contains(t, output, "Expires In:   ⏳ Valid from in 2 days 0 hours")
+}
+
+// writeCertFile writes the given certificates to a new PEM file and returns its path
+func writeCertFile(t *testing.T, certs ...tls.Certificate) string {
+	var data []byte
+	for _, cert := range certs {
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})...)
+	}
+	return writeFile(t, data)
+}
+
+func TestReadCommandVerifiesAgainstCAFile(t *testing.T) {
+	leaf, intermediate, root := buildChain()
+	server := setupTestServerWithCert(t, testutil.Chain(leaf, intermediate))
+	output := runReadCommand(t, server.GetAddress(), "--ca-file", writeCertFile(t, root))
+
+	assert.Contains(t, output, "Verification:   ✅ trusted")
+	assert.Contains(t, output, "Trusted Chain:  example.com → Test Intermediate CA → Test Root CA")
+}
+
+func TestReadCommandVerifiesAgainstCADir(t *testing.T) {
+	leaf, intermediate, root := buildChain()
+	server := setupTestServerWithCert(t, testutil.Chain(leaf, intermediate))
+
+	caDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(path.Join(caDir, "root.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: root.Certificate[0]}), 0644))
+	assert.NoError(t, os.WriteFile(path.Join(caDir, "README"), []byte("not a certificate"), 0644))
+
+	output := runReadCommand(t, server.GetAddress(), "--ca-dir", caDir)
+
+	assert.Contains(t, output, "Verification:   ✅ trusted")
+}
+
+func TestReadCommandUntrustedRoot(t *testing.T) {
+	leaf, intermediate, _ := buildChain()
+	_, _, otherRoot := buildChain()
+	server := setupTestServerWithCert(t, testutil.Chain(leaf, intermediate))
+	output := runReadCommand(t, server.GetAddress(), "--ca-file", writeCertFile(t, otherRoot))
+
+	assert.Contains(t, output, "Verification:  ❌ untrusted: x509: certificate signed by unknown authority")
+}
+
+func TestReadCommandMissingIntermediateIsUntrusted(t *testing.T) {
+	leaf, _, root := buildChain()
+	server := setupTestServerWithCert(t, leaf)
+	output := runReadCommand(t, server.GetAddress(), "--ca-file", writeCertFile(t, root))
+
+	assert.Contains(t, output, "Verification:  ❌ untrusted: x509: certificate signed by unknown authority")
+}
+
+func TestReadCommandHostnameMismatchIsUntrusted(t *testing.T) {
+	_, intermediate, root := buildChain()
+	withoutIP := DefaultCertBuilder().BuildSignedBy(intermediate)
+	server := setupTestServerWithCert(t, testutil.Chain(withoutIP, intermediate))
+	output := runReadCommand(t, server.GetAddress(), "--ca-file", writeCertFile(t, root))
+
+	assert.Contains(t, output, "Verification:  ❌ untrusted: x509: cannot validate certificate for 127.0.0.1 because it doesn't contain any IP SANs")
+}
+
+func TestReadCommandHostnameMatchesIPSAN(t *testing.T) {
+	leaf, intermediate, _ := buildChain()
+	server := setupTestServerWithCert(t, testutil.Chain(leaf, intermediate))
+	output := runReadCommand(t, server.GetAddress())
+
+	assert.Contains(t, output, "Hostname:      ✅ 127.0.0.1 matched 127.0.0.1")
+}
+
+func TestReadCommandServerNameOverrideMatchesWildcard(t *testing.T) {
+	exampleCert := buildExampleCertWithDNSNames("example.com", "*.example.com")
+	server := setupTestServer(t, exampleCert)
+	output := runReadCommand(t, server.GetAddress(), "--servername", "api.example.com")
+
+	assert.Contains(t, output, "Hostname:      ✅ api.example.com matched *.example.com (wildcard)")
+}
+
+func TestReadCommandServerNameMismatch(t *testing.T) {
+	exampleCert := buildExampleCertWithDNSNames("example.com")
+	output := runReadCommand(t, writePEMFile(t, exampleCert), "--servername", "other.com")
+
+	assert.Contains(t, output, "Hostname:      ❌ other.com: x509: certificate is valid for example.com, not other.com")
+}
+
+func TestReadCommandCommonNameOnlyCertificate(t *testing.T) {
+	exampleCert := buildExampleCertThatExpiresIn(tenDays)
+	output := runReadCommand(t, writePEMFile(t, exampleCert), "--servername", "example.com")
+
+	assert.Contains(t, output, "Hostname:      ❌ example.com is only in the Common Name, which modern clients reject without a matching SAN")
+}
+
+func TestReadCommandFileWithoutServerNameSkipsHostname(t *testing.T) {
+	exampleCert := buildExampleCertWithDNSNames("example.com")
+	output := runReadCommand(t, writePEMFile(t, exampleCert))
+
+	assert.NotContains(t, output, "Hostname:")
+}
+
+// setupSNITestServer starts a test server that presents default.example.com
+// without SNI and sni.example.com when asked for it
+func setupSNITestServer(t *testing.T) *testutil.TestServer {
+	t.Helper()
+
+	defaultCert := DefaultCertBuilder().WithCommonName("default.example.com").WithDNSNames("default.example.com").Build()
+	sniCert := DefaultCertBuilder().WithCommonName("sni.example.com").WithDNSNames("sni.example.com").Build()
+
+	return setupTestServerWithCert(t, defaultCert, sniCert)
+}
+
+func TestReadCommandSNI(t *testing.T) {
+	server := setupSNITestServer(t)
+	output := runReadCommand(t, server.GetAddress(), "--sni", "sni.example.com")
+
+	assert.Contains(t, output, "Common Name:  sni.example.com")
+	assert.Contains(t, output, "Hostname:      ✅ sni.example.com matched sni.example.com")
+}
+
+func TestReadCommandNoSNI(t *testing.T) {
+	server := setupSNITestServer(t)
+	output := runReadCommand(t, server.GetAddress(), "--no-sni")
+
+	assert.Contains(t, output, "Common Name:  default.example.com")
+}
+
+func TestReadCommandConnectTo(t *testing.T) {
+	server := setupSNITestServer(t)
+	connectTo := fmt.Sprintf("sni.example.com:443:%s", server.GetAddress())
+	output := runReadCommand(t, "sni.example.com", "--connect-to", connectTo)
+
+	assert.Contains(t, output, "Common Name:  sni.example.com")
+}
+
+func TestReadCommandResolve(t *testing.T) {
+	server := setupSNITestServer(t)
+	_, port, err := net.SplitHostPort(server.GetAddress())
+	assert.NoError(t, err)
+
+	output := runReadCommand(t, "sni.example.com:"+port, "--resolve", fmt.Sprintf("sni.example.com:%s:127.0.0.1", port))
+
+	assert.Contains(t, output, "Common Name:  sni.example.com")
+}
+
+func TestReadCommandHandshakeTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	var out, errOut bytes.Buffer
+	err = Run(&bytes.Buffer{}, &out, &errOut, []string{"read", listener.Addr().String(), "--handshake-timeout", "100ms"})
+
+	assert.EqualError(t, err, "TLS handshake with "+listener.Addr().String()+" timed out")
+}
+
+func TestReadCommandConnection(t *testing.T) {
+	server := setupTestServer(t, buildExampleCertThatExpiresIn(48*time.Hour))
+	output := runReadCommand(t, server.GetAddress(), "--connection", "--alpn", "h2,http/1.1")
+
+	assert.Contains(t, output, "Connection:")
+	assert.Regexp(t, `Address:\s+`+regexp.QuoteMeta(server.GetAddress()), output)
+	assert.Regexp(t, `Server Name:\s+127\.0\.0\.1`, output)
+	assert.Regexp(t, `Version:\s+TLS 1\.3`, output)
+	assert.Regexp(t, `Cipher Suite:\s+TLS_(AES_128_GCM_SHA256|AES_256_GCM_SHA384|CHACHA20_POLY1305_SHA256)`, output)
+	assert.Regexp(t, `Key Exchange:\s+X25519MLKEM768`, output)
+	assert.Regexp(t, `ALPN:\s+h2`, output)
+	assert.Regexp(t, `OCSP Stapled:\s+no`, output)
+	assert.Regexp(t, `SCTs:\s+none in handshake`, output)
+}
+
+func TestReadCommandOffersNoALPNByDefault(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithCert(buildExampleCertThatExpiresIn(48 * time.Hour)).Build()
+	server := setupTestServerWithConfig(t, func(b *testutil.TlsConfigBuilder) *tls.Config {
+		return b.WithCerts(cert).WithNextProtos("dot").Build()
+	})
+
+	output := runReadCommand(t, server.GetAddress(), "--connection")
+
+	assert.Contains(t, output, "Common Name:  example.com")
+	assert.Regexp(t, `ALPN:\s+none`, output)
+}
+
+func TestReadCommandALPN(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithCert(buildExampleCertThatExpiresIn(48 * time.Hour)).Build()
+	server := setupTestServerWithConfig(t, func(b *testutil.TlsConfigBuilder) *tls.Config {
+		return b.WithCerts(cert).WithNextProtos("dot").Build()
+	})
+
+	output := runReadCommand(t, server.GetAddress(), "--connection", "--alpn", "dot")
+
+	assert.Regexp(t, `ALPN:\s+dot`, output)
+}
+
+func TestReadCommandConnectionHiddenByDefault(t *testing.T) {
+	server := setupTestServer(t, buildExampleCertThatExpiresIn(48*time.Hour))
+	output := runReadCommand(t, server.GetAddress())
+
+	assert.NotContains(t, output, "Connection:")
+}
+
+func TestReadCommandConnectionNoSNI(t *testing.T) {
+	server := setupTestServer(t, buildExampleCertThatExpiresIn(48*time.Hour))
+	output := runReadCommand(t, server.GetAddress(), "--connection", "--no-sni")
+
+	assert.Regexp(t, `Server Name:\s+\(none sent\)`, output)
+}
+
+func TestReadCommandJSONConnection(t *testing.T) {
+	server := setupTestServer(t, buildExampleCertThatExpiresIn(48*time.Hour))
+	output := runReadCommand(t, server.GetAddress(), "--output", "json", "--alpn", "h2")
+
+	var doc pretty.Document
+	assert.NoError(t, json.Unmarshal([]byte(output), &doc))
+	if assert.NotNil(t, doc.Connection) {
+		assert.Equal(t, server.GetAddress(), doc.Connection.Address)
+		assert.Equal(t, "TLS 1.3", doc.Connection.Version)
+		assert.Equal(t, "X25519MLKEM768", doc.Connection.KeyExchange)
+		assert.Equal(t, "h2", doc.Connection.ALPN)
+	}
+}
+
+func TestReadCommandJSONFileHasNoConnection(t *testing.T) {
+	path := writeCertFile(t, testutil.NewCertBuilder().WithCert(buildExampleCertThatExpiresIn(48*time.Hour)).Build())
+	output := runReadCommand(t, path, "--output", "json")
+
+	assert.NotContains(t, output, `"connection"`)
+}
+
+func TestReadCommandExtensions(t *testing.T) {
+	_, permitted, _ := net.ParseCIDR("10.0.0.0/8")
+	cert := DefaultCertBuilder().
+		WithKeyUsage(x509.KeyUsageDigitalSignature|x509.KeyUsageCertSign).
+		WithExtKeyUsage(x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth).
+		WithCA(true).
+		WithMaxPathLen(0).
+		BuildCert()
+	cert.SubjectKeyId = []byte{0x01, 0x02, 0xab}
+	cert.CRLDistributionPoints = []string{"http://crl.example.com/ca.crl"}
+	cert.OCSPServer = []string{"http://ocsp.example.com"}
+	cert.IssuingCertificateURL = []string{"http://ca.example.com/ca.crt"}
+	cert.Policies = []x509.OID{mustOID(t, "2.23.140.1.2.1"), mustOID(t, "1.2.3.4")}
+	cert.PermittedDNSDomains = []string{".example.com"}
+	cert.PermittedDNSDomainsCritical = true
+	cert.ExcludedIPRanges = []*net.IPNet{permitted}
+
+	output := runReadCommand(t, writePEMFile(t, cert))
+
+	assert.Contains(t, output, "Extensions:")
+	assert.Regexp(t, `Key Usage:\s+Digital Signature, Certificate Sign \(critical\)`, output)
+	assert.Regexp(t, `Extended Key Usage:\s+Server Authentication, Client Authentication\n`, output)
+	assert.Regexp(t, `Basic Constraints:\s+CA, path length 0 \(critical\)`, output)
+	assert.Regexp(t, `Subject Key ID:\s+01:02:AB`, output)
+	assert.Regexp(t, `CRL Distribution:\s+http://crl\.example\.com/ca\.crl`, output)
+	assert.Regexp(t, `OCSP:\s+http://ocsp\.example\.com`, output)
+	assert.Regexp(t, `CA Issuers:\s+http://ca\.example\.com/ca\.crt`, output)
+	assert.Regexp(t, `Policies:\s+Domain Validated \(2\.23\.140\.1\.2\.1\), 1\.2\.3\.4`, output)
+	assert.Regexp(t, `Name Constraints:\s+permitted DNS \.example\.com; excluded IP 10\.0\.0\.0/8 \(critical\)`, output)
+}
+
+func TestReadCommandExtensionsLeaf(t *testing.T) {
+	leaf, intermediate, _ := buildChain()
+	output := runReadCommand(t, writeCertFile(t, leaf, intermediate))
+
+	assert.Regexp(t, `Key Usage:\s+Digital Signature, Key Encipherment \(critical\)\n`, output)
+	assert.Regexp(t, `Extended Key Usage:\s+Server Authentication\n`, output)
+	assert.Regexp(t, `Authority Key ID:\s+[0-9A-F:]+`, output)
+	assert.Regexp(t, `Key Usage:\s+Certificate Sign \(critical\)\n`, output)
+	assert.Regexp(t, `Basic Constraints:\s+CA, unlimited path length \(critical\)`, output)
+}
+
+func mustOID(t *testing.T, s string) x509.OID {
+	t.Helper()
+
+	oid, err := x509.ParseOID(s)
+	if err != nil {
+		t.Fatalf("failed to parse OID %s: %v", s, err)
+	}
+	return oid
+}
+
+func TestReadCommandKey(t *testing.T) {
+	output := runReadCommand(t, writePEMFile(t, buildExampleCertThatExpiresIn(48*time.Hour)))
+
+	assert.Contains(t, output, "Key:\nAlgorithm:  RSA\nSize:       2048 bits\nExponent:   65537\nSignature:  RSA-SHA256\n")
+}
+
+func TestReadCommandKeyWarnings(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+
+	template := DefaultCertBuilder().WithSignatureAlgorithm(x509.SHA1WithRSA).BuildCert()
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	assert.NoError(t, err)
+
+	output := runReadCommand(t, writeFile(t, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+
+	assert.Regexp(t, `Size:\s+1024 bits ⚠️ below the 2048 bit minimum`, output)
+	assert.Regexp(t, `Signature:\s+RSA-SHA1 ⚠️ SHA-1 is deprecated`, output)
+}
+
+func TestReadCommandJSONKeyWarnings(t *testing.T) {
+	output := runReadCommand(t, writePEMFile(t, buildExampleCertThatExpiresIn(48*time.Hour)), "-o", "json")
+
+	var doc pretty.Document
+	assert.NoError(t, json.Unmarshal([]byte(output), &doc))
+	assert.Equal(t, pretty.PublicKey{Algorithm: "RSA", Size: 2048, Exponent: 65537}, doc.Certificates[0].PublicKey)
+	assert.Equal(t, []string{}, doc.Certificates[0].KeyWarnings)
+}
+
+// buildUnsignedCertDER encodes a certificate for example.com whose key and
+// signature use the algorithms keyOID and sigOID, with random bytes for the
+// key and signature. crypto/x509 parses such a certificate even when it
+// cannot create or verify one.
+func buildUnsignedCertDER(t *testing.T, keyOID, sigOID asn1.ObjectIdentifier, keySize int) []byte {
+	t.Helper()
+
+	name, err := asn1.Marshal(pkix.Name{CommonName: "example.com"}.ToRDNSequence())
+	assert.NoError(t, err)
+
+	key := make([]byte, keySize)
+	signature := make([]byte, 64)
+	_, _ = rand.Read(key)
+	_, _ = rand.Read(signature)
+
+	tbs, err := asn1.Marshal(struct {
+		Version      int `asn1:"optional,explicit,default:0,tag:0"`
+		SerialNumber *big.Int
+		Signature    pkix.AlgorithmIdentifier
+		Issuer       asn1.RawValue
+		Validity     struct{ NotBefore, NotAfter time.Time }
+		Subject      asn1.RawValue
+		PublicKey    struct {
+			Algorithm pkix.AlgorithmIdentifier
+			PublicKey asn1.BitString
+		}
+	}{
+		Version:      2,
+		SerialNumber: big.NewInt(123),
+		Signature:    pkix.AlgorithmIdentifier{Algorithm: sigOID},
+		Issuer:       asn1.RawValue{FullBytes: name},
+		Validity: struct{ NotBefore, NotAfter time.Time }{
+			NotBefore: time.Now().UTC().Truncate(time.Second),
+			NotAfter:  time.Now().Add(tenDays).UTC().Truncate(time.Second),
+		},
+		Subject: asn1.RawValue{FullBytes: name},
+		PublicKey: struct {
+			Algorithm pkix.AlgorithmIdentifier
+			PublicKey asn1.BitString
+		}{
+			Algorithm: pkix.AlgorithmIdentifier{Algorithm: keyOID},
+			PublicKey: asn1.BitString{Bytes: key, BitLength: 8 * len(key)},
+		},
+	})
+	assert.NoError(t, err)
+
+	der, err := asn1.Marshal(struct {
+		TBSCertificate     asn1.RawValue
+		SignatureAlgorithm pkix.AlgorithmIdentifier
+		Signature          asn1.BitString
+	}{
+		TBSCertificate:     asn1.RawValue{FullBytes: tbs},
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: sigOID},
+		Signature:          asn1.BitString{Bytes: signature, BitLength: 8 * len(signature)},
+	})
+	assert.NoError(t, err)
+
+	_, err = x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return der
+}
+
+func TestReadCommandMLDSAKey(t *testing.T) {
+	// The OID of ML-DSA-65, with its 1952 byte public key.
+	mldsa65 := asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 18}
+	path := writeFile(t, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: buildUnsignedCertDER(t, mldsa65, mldsa65, 1952)}))
+
+	output := runReadCommand(t, path)
+	assert.Contains(t, output, "Key:\nAlgorithm:  ML-DSA-65\nSignature:  ML-DSA-65\n")
+
+	var doc pretty.Document
+	assert.NoError(t, json.Unmarshal([]byte(runReadCommand(t, path, "-o", "json")), &doc))
+	assert.Equal(t, pretty.PublicKey{Algorithm: "ML-DSA-65"}, doc.Certificates[0].PublicKey)
+	assert.Equal(t, "ML-DSA-65", doc.Certificates[0].SignatureAlgorithm)
+}
+
+func TestReadCommandUnknownKeyAlgorithmShowsOID(t *testing.T) {
+	unknown := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1}
+	path := writeFile(t, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: buildUnsignedCertDER(t, unknown, unknown, 32)}))
+
+	output := runReadCommand(t, path)
+
+	assert.Contains(t, output, "Key:\nAlgorithm:  1.3.6.1.4.1.99999.1\nSignature:  1.3.6.1.4.1.99999.1\n")
+}
+
+func spkiPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestReadCommandFingerprints(t *testing.T) {
+	leaf, _, _ := buildChain()
+	cert := leaf.Leaf
+	output := runReadCommand(t, writeCertFile(t, leaf))
+
+	sum := sha256.Sum256(cert.Raw)
+	assert.Contains(t, output, "Fingerprints:\n")
+	assert.Regexp(t, `SHA-1:\s+([0-9A-F]{2}:){19}[0-9A-F]{2}\n`, output)
+	assert.Regexp(t, `SHA-256:\s+`+strings.ReplaceAll(fmt.Sprintf("% X", sum[:]), " ", ":")+`\n`, output)
+	assert.Regexp(t, `SPKI Pin:\s+sha256/`+regexp.QuoteMeta(spkiPin(cert))+`\n`, output)
+}
+
+func TestReadCommandJSONFingerprints(t *testing.T) {
+	leaf, _, _ := buildChain()
+	cert := leaf.Leaf
+	output := runReadCommand(t, writeCertFile(t, leaf), "-o", "json")
+
+	var doc pretty.Document
+	assert.NoError(t, json.Unmarshal([]byte(output), &doc))
+	assert.Equal(t, spkiPin(cert), doc.Certificates[0].Fingerprints.SPKISHA256)
+}
+
+func TestReadCommandFingerprintOnly(t *testing.T) {
+	leaf, intermediate, _ := buildChain()
+	server := setupTestServerWithCert(t, testutil.Chain(leaf, intermediate))
+
+	output := runReadCommand(t, "--fingerprint-only", server.GetAddress())
+
+	assert.Equal(t, spkiPin(leaf.Leaf)+"\n"+spkiPin(intermediate.Leaf)+"\n", output)
+}
+
+func TestReadCommandFingerprintOnlySHA256(t *testing.T) {
+	leaf, _, _ := buildChain()
+	cert := leaf.Leaf
+	output := runReadCommand(t, writeCertFile(t, leaf), "--fingerprint-only=sha256")
+
+	sum := sha256.Sum256(cert.Raw)
+	assert.Equal(t, strings.ReplaceAll(fmt.Sprintf("% X", sum[:]), " ", ":")+"\n", output)
+}
+
+func TestReadCommandFingerprintOnlyInvalid(t *testing.T) {
+	var out, errOut bytes.Buffer
+	err := Run(&bytes.Buffer{}, &out, &errOut, []string{"read", "--fingerprint-only=md5", "example.com"})
+
+	assert.EqualError(t, err, "invalid fingerprint: md5 (must be spki, sha256 or sha1)")
+}
+
+func TestReadCommandFingerprintOnlyWithJSON(t *testing.T) {
+	var out, errOut bytes.Buffer
+	err := Run(&bytes.Buffer{}, &out, &errOut, []string{"read", "--fingerprint-only", "-o", "json", "example.com"})
+
+	assert.EqualError(t, err, "--fingerprint-only cannot be combined with --output json")
+}
+
+func buildCertWithSANs(t *testing.T) *x509.Certificate {
+	spiffe, err := url.Parse("spiffe://cluster/ns/foo/sa/bar")
+	assert.NoError(t, err)
+
+	cert := DefaultCertBuilder().
+		WithDNSNames("example.com").
+		WithIPAddresses(net.ParseIP("10.0.0.1"), net.ParseIP("2001:db8::1")).
+		BuildCert()
+	cert.EmailAddresses = []string{"admin@example.com"}
+	cert.URIs = []*url.URL{spiffe}
+	return cert
+}
+
+func TestReadCommandSubjectAlternativeNames(t *testing.T) {
+	output := runReadCommand(t, writePEMFile(t, buildCertWithSANs(t)))
+
+	assert.Contains(t, output, "Common Name:  example.com\n")
+	assert.Contains(t, output, `Subject Alternative Names:
+DNS Names:    [
+                example.com
+              ]
+IPs:          [
+                10.0.0.1,
+                2001:db8::1
+              ]
+Emails:       [
+                admin@example.com
+              ]
+URIs:         [
+                spiffe://cluster/ns/foo/sa/bar
+              ]
+Other Names:  []
+`)
+}
+
+func TestReadCommandOtherNameSAN(t *testing.T) {
+	upn, err := asn1.Marshal("user@example.com")
+	assert.NoError(t, err)
+	otherName, err := asn1.MarshalWithParams(struct {
+		TypeID asn1.ObjectIdentifier
+		Value  asn1.RawValue
+	}{
+		TypeID: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3},
+		Value:  asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: upn},
+	}, "tag:0")
+	assert.NoError(t, err)
+	san, err := asn1.Marshal([]asn1.RawValue{
+		{Class: asn1.ClassContextSpecific, Tag: 2, Bytes: []byte("example.com")},
+		{FullBytes: otherName},
+	})
+	assert.NoError(t, err)
+
+	cert := DefaultCertBuilder().BuildCert()
+	cert.ExtraExtensions = []pkix.Extension{{Id: asn1.ObjectIdentifier{2, 5, 29, 17}, Value: san}}
+
+	output := runReadCommand(t, writePEMFile(t, cert))
+
+	assert.Contains(t, output, `Other Names:  [
+                UPN (1.3.6.1.4.1.311.20.2.3): user@example.com
+              ]`)
+	assert.Contains(t, output, `DNS Names:    [
+                example.com
+              ]`)
+}
+
+func TestReadCommandJSONSubjectAlternativeNames(t *testing.T) {
+	output := runReadCommand(t, writePEMFile(t, buildCertWithSANs(t)), "-o", "json")
+
+	var doc pretty.Document
+	assert.NoError(t, json.Unmarshal([]byte(output), &doc))
+	first := doc.Certificates[0]
+	assert.Equal(t, []string{"example.com"}, first.DNSNames)
+	assert.Equal(t, []string{"10.0.0.1", "2001:db8::1"}, first.IPAddresses)
+	assert.Equal(t, []string{"admin@example.com"}, first.EmailAddresses)
+	assert.Equal(t, []string{"spiffe://cluster/ns/foo/sa/bar"}, first.URIs)
+	assert.Equal(t, []string{}, first.OtherNames)
+}
+
+// buildSCT serializes a v1 SCT from the log whose ID is all logID bytes, with
+// an ECDSA-SHA256 signature.
+func buildSCT(logID byte, timestamp time.Time) []byte {
+	sct := append([]byte{0}, bytes.Repeat([]byte{logID}, 32)...)
+	sct = binary.BigEndian.AppendUint64(sct, uint64(timestamp.UnixMilli()))
+	sct = append(sct, 0, 0, 4, 3, 0, 1, 0)
+	return sct
+}
+
+func buildCertWithEmbeddedSCT(t *testing.T, timestamp time.Time) tls.Certificate {
+	sct := buildSCT(1, timestamp)
+	list := binary.BigEndian.AppendUint16(nil, uint16(len(sct)+2))
+	list = binary.BigEndian.AppendUint16(list, uint16(len(sct)))
+	value, err := asn1.Marshal(append(list, sct...))
+	assert.NoError(t, err)
+
+	cert := DefaultCertBuilder().BuildCert()
+	cert.ExtraExtensions = []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}, Value: value}}
+	return testutil.NewCertBuilder().WithCert(cert).Build()
+}
+
+func writeLogList(t *testing.T) string {
+	return writeFile(t, []byte(`{"operators": [{"name": "Example", "logs": [
+  {"description": "Example 'Log2025'", "log_id": "`+base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))+`"}
+]}]}`))
+}
+
+func TestReadCommandEmbeddedSCTs(t *testing.T) {
+	timestamp := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	path := writeCertFile(t, buildCertWithEmbeddedSCT(t, timestamp))
+
+	output := runReadCommand(t, path)
+
+	assert.Contains(t, output, "Certificate Transparency:\n")
+	assert.Regexp(t, `Log ID:\s+AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=\n`, output)
+	assert.Regexp(t, `Timestamp:\s+2025-01-15T12:00:00Z\n`, output)
+	assert.Regexp(t, `Signature:\s+ECDSA-SHA256\nSource:\s+certificate\n`, output)
+	assert.NotRegexp(t, `Log:\s`, output)
+}
+
+func TestReadCommandSCTLogNames(t *testing.T) {
+	path := writeCertFile(t, buildCertWithEmbeddedSCT(t, time.Now()))
+
+	output := runReadCommand(t, path, "--ct-log-list", writeLogList(t))
+
+	assert.Regexp(t, `Log:\s+Example 'Log2025'\n`, output)
+}
+
+func TestReadCommandHandshakeSCTs(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithCert(buildExampleCertThatExpiresIn(48 * time.Hour)).Build()
+	cert.SignedCertificateTimestamps = [][]byte{buildSCT(2, time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC))}
+	server := setupTestServerWithCert(t, cert)
+
+	output := runReadCommand(t, server.GetAddress(), "--connection")
+
+	assert.Regexp(t, `Log ID:\s+AgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgI=\n`, output)
+	assert.Regexp(t, `Source:\s+handshake\n`, output)
+	assert.Regexp(t, `SCTs:\s+1 in handshake`, output)
+}
+
+func TestReadCommandServerWithoutSCTs(t *testing.T) {
+	server := setupTestServer(t, buildExampleCertThatExpiresIn(48*time.Hour))
+	output := runReadCommand(t, server.GetAddress())
+
+	assert.Contains(t, output, "Certificate Transparency:\nSCTs:  none\n")
+}
+
+func TestReadCommandFileWithoutSCTs(t *testing.T) {
+	output := runReadCommand(t, writePEMFile(t, buildExampleCertThatExpiresIn(48*time.Hour)))
+
+	assert.NotContains(t, output, "Certificate Transparency:")
+}
+
+func TestReadCommandJSONSCTs(t *testing.T) {
+	timestamp := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	path := writeCertFile(t, buildCertWithEmbeddedSCT(t, timestamp))
+
+	output := runReadCommand(t, path, "-o", "json", "--ct-log-list", writeLogList(t))
+
+	var doc pretty.Document
+	assert.NoError(t, json.Unmarshal([]byte(output), &doc))
+	assert.Equal(t, []pretty.SCT{{
+		Source:             "certificate",
+		LogID:              "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=",
+		LogName:            "Example 'Log2025'",
+		Timestamp:          timestamp,
+		SignatureAlgorithm: "ECDSA-SHA256",
+	}}, doc.SCTs)
+}
+
+func TestReadCommandMissingLogList(t *testing.T) {
+	var out, errOut bytes.Buffer
+	err := Run(&bytes.Buffer{}, &out, &errOut, []string{"read", "--ct-log-list", "/does/not/exist.json", "example.com"})
+
+	assert.ErrorContains(t, err, "failed to read log list /does/not/exist.json")
+}
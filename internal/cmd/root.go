@@ -1,30 +1,40 @@
 package cmd
 
 import (
+	"context"
 	"io"
 
 	"github.com/spf13/cobra"
 )
 
-func Run(stdOut, stdErr io.Writer, args []string) error {
-	root := NewRootCmd(stdOut, stdErr)
+func Run(stdIn io.Reader, stdOut, stdErr io.Writer, args []string) error {
+	return RunContext(context.Background(), stdIn, stdOut, stdErr, args)
+}
+
+// RunContext is Run with a context that aborts any connection in progress
+// when it is cancelled, e.g. on Ctrl-C.
+func RunContext(ctx context.Context, stdIn io.Reader, stdOut, stdErr io.Writer, args []string) error {
+	root := NewRootCmd(stdIn, stdOut, stdErr)
 	root.SetArgs(args)
-	return root.Execute()
+	return root.ExecuteContext(ctx)
 }
 
-func NewRootCmd(stdOut, stdErr io.Writer) *cobra.Command {
+func NewRootCmd(stdIn io.Reader, stdOut, stdErr io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "tls",
 		Short: "A friendly TLS certificate inspector",
 		Long:  "tls is a human-friendly CLI for inspecting TLS certificates from hosts and files.",
 	}
 
-	// Make Cobra write to your injected writers
+	// Make Cobra read from and write to your injected streams
+	cmd.SetIn(stdIn)
 	cmd.SetOut(stdOut)
 	cmd.SetErr(stdErr)
 
 	// Add subcommands
-	cmd.AddCommand(NewReadCmd(stdOut, stdErr))
+	cmd.AddCommand(NewReadCmd(stdIn, stdOut, stdErr))
+	cmd.AddCommand(NewCheckCmd(stdIn, stdOut, stdErr))
+	cmd.AddCommand(NewScanCmd(stdIn, stdOut, stdErr))
 
 	return cmd
 }
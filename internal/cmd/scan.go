@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"io"
+
+	"github.com/kevholditch/tls/internal/pretty"
+	"github.com/kevholditch/tls/internal/tls"
+	"github.com/spf13/cobra"
+)
+
+func NewScanCmd(stdIn io.Reader, stdOut, stdErr io.Writer) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "scan",
+		Short: "Probe what a server supports",
+		Long: `Probe a server with many handshakes, each offering only part of what a
+client could, to find out what it supports.`,
+	}
+
+	c.AddCommand(NewScanVersionsCmd(stdIn, stdOut, stdErr))
+	c.AddCommand(NewScanCiphersCmd(stdIn, stdOut, stdErr))
+
+	return c
+}
+
+func NewScanVersionsCmd(stdIn io.Reader, stdOut, stdErr io.Writer) *cobra.Command {
+	var server serverFlags
+	var output string
+
+	c := &cobra.Command{
+		Use:   "versions <target>",
+		Short: "List the TLS protocol versions a server accepts",
+		Long: `Attempt a handshake limited to each of TLS 1.0, 1.1, 1.2 and 1.3 and report
+which ones the server accepts. TLS 1.0 and 1.1 are flagged as deprecated by
+RFC 8996.
+
+Target is a server, given as for the read command, and every flag that
+controls how read connects applies to each handshake. --timeout limits each
+handshake rather than the whole scan.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			serverOpts, err := server.options()
+			if err != nil {
+				return err
+			}
+
+			format, err := pretty.ParseFormat(output)
+			if err != nil {
+				return err
+			}
+
+			results, err := tls.ScanVersions(cmd.Context(), args[0], serverOpts)
+			if err != nil {
+				return err
+			}
+
+			scan := pretty.NewVersionScan(args[0], results)
+			if format == pretty.FormatJSON {
+				return pretty.PrintVersionScanJSON(stdOut, scan)
+			}
+			return pretty.PrintVersionScan(stdOut, scan)
+		},
+	}
+
+	server.register(c.Flags())
+	c.Flags().StringVarP(&output, "output", "o", "text", "output format: text or json")
+
+	return c
+}
+
+func NewScanCiphersCmd(stdIn io.Reader, stdOut, stdErr io.Writer) *cobra.Command {
+	var server serverFlags
+	var output string
+
+	c := &cobra.Command{
+		Use:   "ciphers <target>",
+		Short: "List the cipher suites a server accepts for each TLS version",
+		Long: `Offer every cipher suite Go implements, including insecure ones, one at a
+time for each of TLS 1.0, 1.1, 1.2 and 1.3 and report which ones the server
+accepts. When it accepts more than one, two are offered in both orders to
+tell whether the server enforces its own preference, and if it does the
+suites are listed in its order.
+
+Each suite is graded strong, weak (CBC, 3DES, RC4, or RSA key exchange
+without forward secrecy) or insecure.
+
+Target is a server, given as for the read command, and every flag that
+controls how read connects applies to each handshake. --timeout limits each
+handshake rather than the whole scan.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			serverOpts, err := server.options()
+			if err != nil {
+				return err
+			}
+
+			format, err := pretty.ParseFormat(output)
+			if err != nil {
+				return err
+			}
+
+			results, err := tls.ScanCiphers(cmd.Context(), args[0], serverOpts)
+			if err != nil {
+				return err
+			}
+
+			scan := pretty.NewCipherScan(args[0], results)
+			if format == pretty.FormatJSON {
+				return pretty.PrintCipherScanJSON(stdOut, scan)
+			}
+			return pretty.PrintCipherScan(stdOut, scan)
+		},
+	}
+
+	server.register(c.Flags())
+	c.Flags().StringVarP(&output, "output", "o", "text", "output format: text or json")
+
+	return c
+}
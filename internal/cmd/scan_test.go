@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/kevholditch/tls/internal/pretty"
+	"github.com/kevholditch/tls/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// runScanCommand runs a scan subcommand and returns the output
+func runScanCommand(t *testing.T, scanArgs ...string) string {
+	t.Helper()
+
+	var out, errOut bytes.Buffer
+	err := Run(&bytes.Buffer{}, &out, &errOut, append([]string{"scan"}, scanArgs...))
+	if err != nil {
+		t.Fatalf("failed to scan: %v", err)
+	}
+
+	return out.String()
+}
+
+func TestScanVersionsCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		minVersion uint16
+		maxVersion uint16
+		expected   []string
+	}{
+		{"modern", tls.VersionTLS12, tls.VersionTLS13, []string{
+			`TLS 1\.0:\s+✅ rejected`,
+			`TLS 1\.1:\s+✅ rejected`,
+			`TLS 1\.2:\s+✅ accepted`,
+			`TLS 1\.3:\s+✅ accepted`,
+		}},
+		{"legacy", tls.VersionTLS10, tls.VersionTLS12, []string{
+			`TLS 1\.0:\s+⚠️ accepted \(deprecated by RFC 8996\)`,
+			`TLS 1\.1:\s+⚠️ accepted \(deprecated by RFC 8996\)`,
+			`TLS 1\.2:\s+✅ accepted`,
+			`TLS 1\.3:\s+❌ rejected`,
+		}},
+		{"tls 1.3 only", tls.VersionTLS13, tls.VersionTLS13, []string{
+			`TLS 1\.2:\s+❌ rejected`,
+			`TLS 1\.3:\s+✅ accepted`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := testutil.NewCertBuilder().WithCert(buildExampleCertThatExpiresIn(48 * time.Hour)).Build()
+			server := setupTestServerWithVersions(t, tt.minVersion, tt.maxVersion, cert)
+
+			output := runScanCommand(t, "versions", server.GetAddress())
+
+			assert.Contains(t, output, "Protocol versions for "+server.GetAddress()+":")
+			for _, expected := range tt.expected {
+				assert.Regexp(t, regexp.MustCompile(expected), output)
+			}
+		})
+	}
+}
+
+func TestScanVersionsCommandJSON(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithCert(buildExampleCertThatExpiresIn(48 * time.Hour)).Build()
+	server := setupTestServerWithVersions(t, tls.VersionTLS11, tls.VersionTLS12, cert)
+
+	output := runScanCommand(t, "versions", server.GetAddress(), "-o", "json")
+
+	var scan pretty.VersionScan
+	assert.NoError(t, json.Unmarshal([]byte(output), &scan))
+	assert.Equal(t, server.GetAddress(), scan.Target)
+	if assert.Len(t, scan.Versions, 4) {
+		assert.Equal(t, pretty.Version{Version: "TLS 1.1", Accepted: true, Deprecated: true}, scan.Versions[1])
+		assert.Equal(t, pretty.Version{Version: "TLS 1.2", Accepted: true}, scan.Versions[2])
+		assert.False(t, scan.Versions[0].Accepted)
+		assert.NotEmpty(t, scan.Versions[0].Error)
+	}
+}
+
+func TestScanVersionsCommandUnreachable(t *testing.T) {
+	var out, errOut bytes.Buffer
+	err := Run(&bytes.Buffer{}, &out, &errOut, []string{"scan", "versions", "127.0.0.1:1"})
+
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+func TestScanCiphersCommand(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithCert(buildExampleCertThatExpiresIn(48 * time.Hour)).Build()
+	server := setupTestServerWithConfig(t, func(b *testutil.TlsConfigBuilder) *tls.Config {
+		return b.WithCerts(cert).
+			WithMinimumTLSVersion(tls.VersionTLS12).
+			WithMaximumTLSVersion(tls.VersionTLS13).
+			WithCipherSuites(
+				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+				tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+				tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+				tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA,
+			).
+			Build()
+	})
+
+	output := runScanCommand(t, "ciphers", server.GetAddress())
+
+	assert.Contains(t, output, "Cipher suites for "+server.GetAddress()+":")
+	assert.Regexp(t, `TLS 1\.0:\s+not accepted`, output)
+	assert.Regexp(t, `TLS 1\.1:\s+not accepted`, output)
+	assert.Regexp(t, `TLS 1\.2:\s+server preference order`, output)
+	assert.Regexp(t, `TLS 1\.3:\s+\w+ preference order`, output)
+	assert.Contains(t, output, "  ✅ TLS_AES_128_GCM_SHA256\n")
+	assert.Contains(t, output, "  ✅ TLS_AES_256_GCM_SHA384\n")
+	assert.Contains(t, output, "  ✅ TLS_CHACHA20_POLY1305_SHA256\n")
+	assert.Contains(t, output, "  ✅ TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256\n")
+	assert.Contains(t, output, "  ⚠️ TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA (weak: CBC)\n")
+	assert.Contains(t, output, "  ❌ TLS_RSA_WITH_AES_128_CBC_SHA (insecure: CBC, no PFS)\n")
+	assert.Contains(t, output, "  ❌ TLS_RSA_WITH_3DES_EDE_CBC_SHA (insecure: CBC, 3DES, no PFS)\n")
+	assert.NotContains(t, output, "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256")
+}
+
+func TestScanCiphersCommandJSON(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithCert(buildExampleCertThatExpiresIn(48 * time.Hour)).Build()
+	server := setupTestServerWithConfig(t, func(b *testutil.TlsConfigBuilder) *tls.Config {
+		return b.WithCerts(cert).
+			WithMinimumTLSVersion(tls.VersionTLS12).
+			WithMaximumTLSVersion(tls.VersionTLS12).
+			WithCipherSuites(tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_RSA_WITH_AES_128_CBC_SHA).
+			Build()
+	})
+
+	output := runScanCommand(t, "ciphers", server.GetAddress(), "-o", "json")
+
+	var scan pretty.CipherScan
+	assert.NoError(t, json.Unmarshal([]byte(output), &scan))
+	if assert.Len(t, scan.Versions, 4) {
+		tls12 := scan.Versions[2]
+		assert.Equal(t, "TLS 1.2", tls12.Version)
+		assert.True(t, tls12.Accepted)
+		if assert.NotNil(t, tls12.ServerPreference) {
+			assert.True(t, *tls12.ServerPreference)
+		}
+		assert.Equal(t, []pretty.CipherSuite{
+			{Name: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", ID: "0xC02F", Grade: "strong", Weaknesses: []string{}},
+			{Name: "TLS_RSA_WITH_AES_128_CBC_SHA", ID: "0x002F", Grade: "insecure", Weaknesses: []string{"CBC", "no PFS"}},
+		}, tls12.CipherSuites)
+
+		assert.False(t, scan.Versions[3].Accepted)
+		assert.Nil(t, scan.Versions[3].ServerPreference)
+	}
+}
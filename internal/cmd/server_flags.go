@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kevholditch/tls/internal/tls"
+	"github.com/spf13/pflag"
+)
+
+// serverFlags are the flags shared by every command that connects to a server.
+type serverFlags struct {
+	sni       string
+	noSNI     bool
+	connectTo []string
+	resolve   []string
+	startTLS  string
+	send      string
+	expect    string
+	proxy     string
+	timeout   time.Duration
+	handshake time.Duration
+	alpn      []string
+}
+
+func (f *serverFlags) register(flags *pflag.FlagSet) {
+	flags.StringVar(&f.sni, "sni", "", "server name to send in the handshake instead of the target host")
+	flags.BoolVar(&f.noSNI, "no-sni", false, "send no server name, to see the server's default certificate")
+	flags.StringArrayVar(&f.connectTo, "connect-to", nil, "connect to HOST2:PORT2 instead of HOST1:PORT1, given as HOST1:PORT1:HOST2:PORT2")
+	flags.StringArrayVar(&f.resolve, "resolve", nil, "connect to ADDRESS for HOST:PORT, given as HOST:PORT:ADDRESS")
+	flags.StringVar(&f.startTLS, "starttls", "auto", "upgrade a plaintext protocol before the handshake: auto, none, smtp, imap, pop3, ftp, postgres, mysql, ldap, xmpp or generic")
+	flags.StringVar(&f.send, "starttls-send", "", "line to send to start TLS with --starttls generic")
+	flags.StringVar(&f.expect, "starttls-expect", "", "reply prefix that means the server is ready for TLS with --starttls generic")
+	flags.StringVar(&f.proxy, "proxy", "", "connect through an http://, https:// or socks5:// proxy, with optional user:password@; defaults to HTTPS_PROXY or ALL_PROXY")
+	flags.DurationVar(&f.timeout, "timeout", 30*time.Second, "give up if connecting and reading the certificates takes longer than this, 0 for no limit")
+	flags.DurationVar(&f.handshake, "handshake-timeout", 10*time.Second, "give up if the STARTTLS exchange and TLS handshake take longer than this, 0 for no limit")
+	flags.StringSliceVar(&f.alpn, "alpn", nil, "comma separated application protocols to offer with ALPN, e.g. h2,http/1.1; none by default")
+}
+
+func (f *serverFlags) options() (tls.ServerOptions, error) {
+	startTLS, err := tls.ParseStartTLS(f.startTLS)
+	if err != nil {
+		return tls.ServerOptions{}, err
+	}
+
+	if startTLS == tls.StartTLSGeneric && f.expect == "" {
+		return tls.ServerOptions{}, fmt.Errorf("--starttls generic requires --starttls-expect")
+	}
+
+	opts := tls.ServerOptions{
+		SNI:      f.sni,
+		NoSNI:    f.noSNI,
+		StartTLS: startTLS,
+		Generic:  tls.GenericStartTLS{Send: f.send, Expect: f.expect},
+		Proxy:    tls.ProxyFromEnvironment,
+
+		Timeout:          f.timeout,
+		HandshakeTimeout: f.handshake,
+		ALPN:             f.alpn,
+	}
+
+	if f.proxy != "" {
+		proxy, err := tls.ParseProxy(f.proxy)
+		if err != nil {
+			return tls.ServerOptions{}, err
+		}
+		opts.Proxy = tls.FixedProxy(proxy)
+	}
+
+	// --resolve pins an exact host and port, so it takes precedence over the
+	// possibly wildcard --connect-to rules.
+	for _, s := range f.resolve {
+		rule, err := tls.ParseResolve(s)
+		if err != nil {
+			return tls.ServerOptions{}, err
+		}
+		opts.ConnectTo = append(opts.ConnectTo, rule)
+	}
+
+	for _, s := range f.connectTo {
+		rule, err := tls.ParseConnectTo(s)
+		if err != nil {
+			return tls.ServerOptions{}, err
+		}
+		opts.ConnectTo = append(opts.ConnectTo, rule)
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/kevholditch/tls/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupStartTLSServer creates and starts a STARTTLS test server for protocol
+func setupStartTLSServer(t *testing.T, protocol string, refuse bool) *testutil.StartTLSServer {
+	t.Helper()
+
+	cert := DefaultCertBuilder().WithCommonName(protocol + ".example.com").Build()
+	server, err := testutil.NewStartTLSServer(protocol, func(b *testutil.TlsConfigBuilder) *tls.Config {
+		return b.WithCerts(cert).Build()
+	})
+	if err != nil {
+		t.Fatalf("failed to create test server: %v", err)
+	}
+	if refuse {
+		server.Refuse()
+	}
+
+	ready := make(chan struct{})
+	go func() {
+		if err := server.Start(ready); err != nil {
+			t.Errorf("test server error: %v", err)
+		}
+	}()
+
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for server to start")
+	}
+
+	t.Cleanup(func() {
+		if err := server.Stop(); err != nil {
+			t.Errorf("failed to stop server: %v", err)
+		}
+	})
+
+	return server
+}
+
+func TestReadCommandStartTLS(t *testing.T) {
+	for _, protocol := range []string{"smtp", "imap", "pop3", "ftp", "postgres", "mysql", "ldap", "xmpp"} {
+		t.Run(protocol, func(t *testing.T) {
+			server := setupStartTLSServer(t, protocol, false)
+			output := runReadCommand(t, server.GetAddress(), "--starttls", protocol)
+
+			assert.Contains(t, output, "Common Name:  "+protocol+".example.com")
+		})
+	}
+}
+
+func TestReadCommandStartTLSFromURLScheme(t *testing.T) {
+	server := setupStartTLSServer(t, "smtp", false)
+	output := runReadCommand(t, "smtp://"+server.GetAddress())
+
+	assert.Contains(t, output, "Common Name:  smtp.example.com")
+}
+
+func TestReadCommandLDAPURL(t *testing.T) {
+	server := setupStartTLSServer(t, "ldap", false)
+	output := runReadCommand(t, "ldap://"+server.GetAddress())
+
+	assert.Contains(t, output, "Common Name:  ldap.example.com")
+}
+
+func TestReadCommandStartTLSOverridesURLScheme(t *testing.T) {
+	server := setupStartTLSServer(t, "imap", false)
+	output := runReadCommand(t, "smtp://"+server.GetAddress(), "--starttls", "imap")
+
+	assert.Contains(t, output, "Common Name:  imap.example.com")
+}
+
+func TestReadCommandStartTLSRefused(t *testing.T) {
+	tests := []struct {
+		protocol string
+		expected string
+	}{
+		{"smtp", "smtp server refused to start TLS: STARTTLS not advertised in EHLO reply"},
+		{"imap", "imap server refused to start TLS: a001 BAD STARTTLS not available"},
+		{"pop3", "pop3 server refused to start TLS: -ERR STLS not available"},
+		{"ftp", "ftp server refused to start TLS: 502 AUTH TLS not supported"},
+		{"postgres", "postgres server refused to start TLS: server does not support SSL"},
+		{"mysql", "mysql server refused to start TLS: server does not support SSL"},
+		{"ldap", "ldap server refused to start TLS: result code 2: unsupported"},
+		{"xmpp", "xmpp server refused to start TLS: starttls not offered in stream features"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.protocol, func(t *testing.T) {
+			server := setupStartTLSServer(t, tt.protocol, true)
+
+			var out, errOut bytes.Buffer
+			err := Run(&bytes.Buffer{}, &out, &errOut, []string{"read", server.GetAddress(), "--starttls", tt.protocol})
+
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestReadCommandGenericStartTLS(t *testing.T) {
+	server := setupStartTLSServer(t, "generic", false)
+	output := runReadCommand(t, server.GetAddress(), "--starttls", "generic", "--starttls-send", "STARTTLS", "--starttls-expect", "OK")
+
+	assert.Contains(t, output, "Common Name:  generic.example.com")
+}
+
+func TestReadCommandGenericStartTLSRefused(t *testing.T) {
+	server := setupStartTLSServer(t, "generic", true)
+
+	var out, errOut bytes.Buffer
+	err := Run(&bytes.Buffer{}, &out, &errOut, []string{"read", server.GetAddress(), "--starttls", "generic", "--starttls-send", "STARTTLS", "--starttls-expect", "OK"})
+
+	assert.EqualError(t, err, `generic server refused to start TLS: connection closed before a reply starting "OK", last line "NO"`)
+}
+
+func TestReadCommandGenericStartTLSRequiresExpect(t *testing.T) {
+	var out, errOut bytes.Buffer
+	err := Run(&bytes.Buffer{}, &out, &errOut, []string{"read", "example.com", "--starttls", "generic", "--starttls-send", "STARTTLS"})
+
+	assert.EqualError(t, err, "--starttls generic requires --starttls-expect")
+}
@@ -0,0 +1,93 @@
+package pretty
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"time"
+)
+
+// PrintChain prints every certificate in certs, each under a header showing
+// its position, followed by a summary of which certificate issued which.
+func PrintChain(writer io.Writer, certs []*x509.Certificate, now time.Time) error {
+	for i, cert := range certs {
+		if i > 0 {
+			if _, err := fmt.Fprintln(writer); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(writer, "Certificate %d of %d (%s)\n", i+1, len(certs), position(cert)); err != nil {
+			return err
+		}
+		if err := Print(writer, cert, now); err != nil {
+			return err
+		}
+	}
+
+	if len(certs) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(writer); err != nil {
+		return err
+	}
+	return printChainSummary(writer, certs)
+}
+
+func printChainSummary(writer io.Writer, certs []*x509.Certificate) error {
+	ew := &errorWriter{w: writer}
+	ew.printLine("Chain:")
+
+	for i, cert := range certs {
+		ew.printLine(fmt.Sprintf("  [%d] %s (%s)", i+1, displayName(cert.Subject), position(cert)))
+
+		switch {
+		case isSelfSigned(cert):
+			ew.printLine("      self-signed")
+		case i+1 < len(certs) && signedBy(cert, certs[i+1]):
+			ew.printLine(fmt.Sprintf("      issued by [%d] %s", i+2, displayName(certs[i+1].Subject)))
+		case i+1 < len(certs):
+			ew.printLine(fmt.Sprintf("      ⚠️ issued by %s, which is not the next certificate", displayName(cert.Issuer)))
+		default:
+			ew.printLine(fmt.Sprintf("      issued by %s (not presented)", displayName(cert.Issuer)))
+		}
+	}
+
+	return ew.err
+}
+
+// position describes the role a certificate plays in a chain. A self-signed
+// certificate that is not a CA, as many internal servers use, is a leaf.
+func position(cert *x509.Certificate) string {
+	switch {
+	case cert.IsCA && isSelfSigned(cert):
+		return "root"
+	case cert.IsCA:
+		return "intermediate"
+	default:
+		return "leaf"
+	}
+}
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	if !bytes.Equal(cert.RawSubject, cert.RawIssuer) {
+		return false
+	}
+	return signedBy(cert, cert)
+}
+
+// signedBy reports whether issuer's key produced the signature on cert. Unlike
+// CheckSignatureFrom it does not require issuer to be a valid CA, so it also
+// recognises self-signed leaf certificates.
+func signedBy(cert, issuer *x509.Certificate) bool {
+	return issuer.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
+
+func displayName(name pkix.Name) string {
+	if name.CommonName != "" {
+		return name.CommonName
+	}
+	return name.String()
+}
@@ -0,0 +1,85 @@
+package pretty
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Connection describes the parameters negotiated with a server.
+type Connection struct {
+	// Address is the address that was dialed.
+	Address string `json:"address"`
+	// ServerName is the SNI sent in the handshake, empty when none was sent.
+	ServerName  string `json:"server_name"`
+	Version     string `json:"version"`
+	CipherSuite string `json:"cipher_suite"`
+	// KeyExchange is the group used for the key exchange, e.g. X25519 or
+	// the post-quantum hybrid X25519MLKEM768. It is empty for RSA key
+	// exchange, which uses no group.
+	KeyExchange string `json:"key_exchange,omitempty"`
+	// ALPN is the application protocol the server picked, if any.
+	ALPN        string `json:"alpn,omitempty"`
+	OCSPStapled bool   `json:"ocsp_stapled"`
+	// SCTs counts the signed certificate timestamps sent in the handshake.
+	SCTs int `json:"scts"`
+}
+
+// NewConnection builds a Connection from the state of a handshake with the
+// server at address, which was sent serverName as its SNI.
+func NewConnection(address, serverName string, state *tls.ConnectionState) *Connection {
+	c := &Connection{
+		Address:     address,
+		ServerName:  serverName,
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ALPN:        state.NegotiatedProtocol,
+		OCSPStapled: len(state.OCSPResponse) > 0,
+		SCTs:        len(state.SignedCertificateTimestamps),
+	}
+	if state.CurveID != 0 {
+		c.KeyExchange = state.CurveID.String()
+	}
+	return c
+}
+
+// PrintConnection prints the parameters negotiated with the server.
+func PrintConnection(writer io.Writer, c *Connection) error {
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+
+	ew := &errorWriter{w: w}
+	ew.newLine()
+	ew.printLine("Connection:")
+	ew.printKV("Address", c.Address)
+	ew.printKV("Server Name", orNone(c.ServerName, "(none sent)"))
+	ew.printKV("Version", c.Version)
+	ew.printKV("Cipher Suite", c.CipherSuite)
+	ew.printKV("Key Exchange", orNone(c.KeyExchange, "none (RSA)"))
+	ew.printKV("ALPN", orNone(c.ALPN, "none"))
+	ew.printKV("OCSP Stapled", yesNo(c.OCSPStapled))
+	if c.SCTs > 0 {
+		ew.printKV("SCTs", fmt.Sprintf("%d in handshake", c.SCTs))
+	} else {
+		ew.printKV("SCTs", "none in handshake")
+	}
+
+	if ew.err != nil {
+		return ew.err
+	}
+	return w.Flush()
+}
+
+func orNone(s, none string) string {
+	if s == "" {
+		return none
+	}
+	return s
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
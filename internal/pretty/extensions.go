@@ -0,0 +1,200 @@
+package pretty
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Object identifiers of the extensions extensionFields shows, used to tell
+// whether each one is critical.
+var (
+	oidKeyUsage            = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtKeyUsage         = asn1.ObjectIdentifier{2, 5, 29, 37}
+	oidBasicConstraints    = asn1.ObjectIdentifier{2, 5, 29, 19}
+	oidNameConstraints     = asn1.ObjectIdentifier{2, 5, 29, 30}
+	oidCertificatePolicies = asn1.ObjectIdentifier{2, 5, 29, 32}
+)
+
+var keyUsageNames = []struct {
+	usage x509.KeyUsage
+	name  string
+}{
+	{x509.KeyUsageDigitalSignature, "Digital Signature"},
+	{x509.KeyUsageContentCommitment, "Content Commitment"},
+	{x509.KeyUsageKeyEncipherment, "Key Encipherment"},
+	{x509.KeyUsageDataEncipherment, "Data Encipherment"},
+	{x509.KeyUsageKeyAgreement, "Key Agreement"},
+	{x509.KeyUsageCertSign, "Certificate Sign"},
+	{x509.KeyUsageCRLSign, "CRL Sign"},
+	{x509.KeyUsageEncipherOnly, "Encipher Only"},
+	{x509.KeyUsageDecipherOnly, "Decipher Only"},
+}
+
+var extKeyUsageNames = map[x509.ExtKeyUsage]string{
+	x509.ExtKeyUsageAny:                            "Any",
+	x509.ExtKeyUsageServerAuth:                     "Server Authentication",
+	x509.ExtKeyUsageClientAuth:                     "Client Authentication",
+	x509.ExtKeyUsageCodeSigning:                    "Code Signing",
+	x509.ExtKeyUsageEmailProtection:                "Email Protection",
+	x509.ExtKeyUsageIPSECEndSystem:                 "IPSec End System",
+	x509.ExtKeyUsageIPSECTunnel:                    "IPSec Tunnel",
+	x509.ExtKeyUsageIPSECUser:                      "IPSec User",
+	x509.ExtKeyUsageTimeStamping:                   "Time Stamping",
+	x509.ExtKeyUsageOCSPSigning:                    "OCSP Signing",
+	x509.ExtKeyUsageMicrosoftServerGatedCrypto:     "Microsoft Server Gated Crypto",
+	x509.ExtKeyUsageNetscapeServerGatedCrypto:      "Netscape Server Gated Crypto",
+	x509.ExtKeyUsageMicrosoftCommercialCodeSigning: "Microsoft Commercial Code Signing",
+	x509.ExtKeyUsageMicrosoftKernelCodeSigning:     "Microsoft Kernel Code Signing",
+}
+
+// policyNames names the certificate policies a browser cares about, the
+// CA/Browser Forum validation levels.
+var policyNames = map[string]string{
+	"2.5.29.32.0":    "Any Policy",
+	"2.23.140.1.1":   "Extended Validation",
+	"2.23.140.1.2.1": "Domain Validated",
+	"2.23.140.1.2.2": "Organization Validated",
+	"2.23.140.1.2.3": "Individual Validated",
+}
+
+// field is a labelled value in the printed certificate.
+type field struct {
+	key   string
+	value string
+}
+
+// extensionFields describes the extensions that explain what a certificate
+// may be used for and where to check its revocation, skipping absent ones.
+func extensionFields(cert *x509.Certificate) []field {
+	var fields []field
+	add := func(key, value string) {
+		fields = append(fields, field{key: key, value: value})
+	}
+
+	if cert.KeyUsage != 0 {
+		add("Key Usage", critical(cert, oidKeyUsage, formatKeyUsage(cert.KeyUsage)))
+	}
+	if len(cert.ExtKeyUsage) > 0 || len(cert.UnknownExtKeyUsage) > 0 {
+		add("Extended Key Usage", critical(cert, oidExtKeyUsage, formatExtKeyUsage(cert)))
+	}
+	if cert.BasicConstraintsValid {
+		add("Basic Constraints", critical(cert, oidBasicConstraints, formatBasicConstraints(cert)))
+	}
+	if len(cert.SubjectKeyId) > 0 {
+		add("Subject Key ID", colonHex(cert.SubjectKeyId))
+	}
+	if len(cert.AuthorityKeyId) > 0 {
+		add("Authority Key ID", colonHex(cert.AuthorityKeyId))
+	}
+	if len(cert.CRLDistributionPoints) > 0 {
+		add("CRL Distribution", strings.Join(cert.CRLDistributionPoints, ", "))
+	}
+	if len(cert.OCSPServer) > 0 {
+		add("OCSP", strings.Join(cert.OCSPServer, ", "))
+	}
+	if len(cert.IssuingCertificateURL) > 0 {
+		add("CA Issuers", strings.Join(cert.IssuingCertificateURL, ", "))
+	}
+	if len(cert.Policies) > 0 {
+		add("Policies", critical(cert, oidCertificatePolicies, formatPolicies(cert)))
+	}
+	if constraints := formatNameConstraints(cert); constraints != "" {
+		add("Name Constraints", critical(cert, oidNameConstraints, constraints))
+	}
+	return fields
+}
+
+func formatKeyUsage(usage x509.KeyUsage) string {
+	var names []string
+	for _, ku := range keyUsageNames {
+		if usage&ku.usage != 0 {
+			names = append(names, ku.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func formatExtKeyUsage(cert *x509.Certificate) string {
+	var names []string
+	for _, eku := range cert.ExtKeyUsage {
+		if name, ok := extKeyUsageNames[eku]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, fmt.Sprintf("unknown (%d)", eku))
+		}
+	}
+	for _, oid := range cert.UnknownExtKeyUsage {
+		names = append(names, oid.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+func formatBasicConstraints(cert *x509.Certificate) string {
+	if !cert.IsCA {
+		return "not a CA"
+	}
+
+	switch {
+	case cert.MaxPathLen > 0:
+		return fmt.Sprintf("CA, path length %d", cert.MaxPathLen)
+	case cert.MaxPathLen == 0 && cert.MaxPathLenZero:
+		return "CA, path length 0"
+	default:
+		return "CA, unlimited path length"
+	}
+}
+
+func formatPolicies(cert *x509.Certificate) string {
+	var policies []string
+	for _, oid := range cert.Policies {
+		if name, ok := policyNames[oid.String()]; ok {
+			policies = append(policies, fmt.Sprintf("%s (%s)", name, oid))
+		} else {
+			policies = append(policies, oid.String())
+		}
+	}
+	return strings.Join(policies, ", ")
+}
+
+// formatNameConstraints lists every permitted and excluded subtree, or
+// returns "" when the certificate has no name constraints.
+func formatNameConstraints(cert *x509.Certificate) string {
+	var parts []string
+	add := func(label string, values []string) {
+		if len(values) > 0 {
+			parts = append(parts, label+" "+strings.Join(values, ", "))
+		}
+	}
+
+	add("permitted DNS", cert.PermittedDNSDomains)
+	add("excluded DNS", cert.ExcludedDNSDomains)
+	add("permitted IP", ipNetStrings(cert.PermittedIPRanges))
+	add("excluded IP", ipNetStrings(cert.ExcludedIPRanges))
+	add("permitted email", cert.PermittedEmailAddresses)
+	add("excluded email", cert.ExcludedEmailAddresses)
+	add("permitted URI", cert.PermittedURIDomains)
+	add("excluded URI", cert.ExcludedURIDomains)
+
+	return strings.Join(parts, "; ")
+}
+
+func ipNetStrings(nets []*net.IPNet) []string {
+	var s []string
+	for _, n := range nets {
+		s = append(s, n.String())
+	}
+	return s
+}
+
+// critical marks value as critical when the certificate's extension oid is.
+func critical(cert *x509.Certificate, oid asn1.ObjectIdentifier, value string) string {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oid) && ext.Critical {
+			return value + " (critical)"
+		}
+	}
+	return value
+}
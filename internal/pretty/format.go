@@ -0,0 +1,46 @@
+package pretty
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+func ParseFormat(s string) (Format, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	format := Format(s)
+	switch format {
+	case FormatText, FormatJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid output: %s (must be text or json)", s)
+	}
+}
+
+// Fingerprint is the digest PrintFingerprints writes for each certificate.
+type Fingerprint string
+
+const (
+	// FingerprintSPKI is the base64 SHA-256 of the subject public key info,
+	// the pin used by HPKP, Envoy and OkHttp.
+	FingerprintSPKI   Fingerprint = "spki"
+	FingerprintSHA256 Fingerprint = "sha256"
+	FingerprintSHA1   Fingerprint = "sha1"
+)
+
+func ParseFingerprint(s string) (Fingerprint, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	fingerprint := Fingerprint(s)
+	switch fingerprint {
+	case FingerprintSPKI, FingerprintSHA256, FingerprintSHA1:
+		return fingerprint, nil
+	default:
+		return "", fmt.Errorf("invalid fingerprint: %s (must be spki, sha256 or sha1)", s)
+	}
+}
@@ -0,0 +1,128 @@
+package pretty
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/kevholditch/tls/internal/tls"
+)
+
+// Document is the top level object written by PrintJSON. Its field names are
+// part of the CLI's interface: add fields freely but never rename or remove
+// them, scripts depend on them.
+type Document struct {
+	Certificates []Certificate `json:"certificates"`
+	Verification *Verification `json:"verification,omitempty"`
+	// Connection is present when the certificates were read from a server.
+	Connection *Connection `json:"connection,omitempty"`
+	// SCTs are the signed certificate timestamps of the leaf, embedded in it
+	// or sent in the handshake.
+	SCTs []SCT `json:"scts,omitempty"`
+}
+
+// Certificate describes a single certificate in a Document.
+type Certificate struct {
+	// Index is the 1-based position of the certificate in the chain or file.
+	Index int `json:"index"`
+	// Position is "leaf", "intermediate" or "root".
+	Position       string   `json:"position"`
+	CommonName     string   `json:"common_name"`
+	Subject        string   `json:"subject"`
+	DNSNames       []string `json:"dns_names"`
+	IPAddresses    []string `json:"ip_addresses"`
+	EmailAddresses []string `json:"email_addresses"`
+	// URIs include SPIFFE IDs, e.g. "spiffe://cluster/ns/foo/sa/bar".
+	URIs []string `json:"uris"`
+	// OtherNames are otherName SANs as "type: value", e.g.
+	// "UPN (1.3.6.1.4.1.311.20.2.3): user@example.com".
+	OtherNames         []string  `json:"other_names"`
+	NotBefore          time.Time `json:"not_before"`
+	NotAfter           time.Time `json:"not_after"`
+	SecondsUntilExpiry int64     `json:"seconds_until_expiry"`
+	// ValidityStatus is "valid", "expired" or "not_yet_valid".
+	ValidityStatus     string       `json:"validity_status"`
+	Issuer             string       `json:"issuer"`
+	Serial             string       `json:"serial"`
+	SignatureAlgorithm string       `json:"signature_algorithm"`
+	PublicKey          PublicKey    `json:"public_key"`
+	Fingerprints       Fingerprints `json:"fingerprints"`
+	// KeyWarnings explains what is deprecated about the key or signature,
+	// e.g. "SHA-1 is deprecated".
+	KeyWarnings []string `json:"key_warnings"`
+}
+
+// PublicKey describes the subject public key of a certificate.
+type PublicKey struct {
+	// Algorithm is e.g. "RSA", "ECDSA" or "Ed25519".
+	Algorithm string `json:"algorithm"`
+	// Size is the key size in bits.
+	Size int `json:"size"`
+	// Curve is the named curve of an ECDSA key, empty otherwise.
+	Curve string `json:"curve,omitempty"`
+	// Exponent is the public exponent of an RSA key, zero otherwise.
+	Exponent int `json:"exponent,omitempty"`
+}
+
+// Fingerprints holds colon separated hex digests of the DER certificate, and
+// the base64 SHA-256 of its subject public key info used for pinning.
+type Fingerprints struct {
+	SHA1       string `json:"sha1"`
+	SHA256     string `json:"sha256"`
+	SPKISHA256 string `json:"spki_sha256"`
+}
+
+// NewDocument builds the Document describing certs as of now.
+func NewDocument(certs []*x509.Certificate, now time.Time) Document {
+	doc := Document{Certificates: make([]Certificate, 0, len(certs))}
+
+	for i, cert := range certs {
+		doc.Certificates = append(doc.Certificates, Certificate{
+			Index:              i + 1,
+			Position:           position(cert),
+			CommonName:         cert.Subject.CommonName,
+			Subject:            cert.Subject.String(),
+			DNSNames:           orEmpty(cert.DNSNames),
+			IPAddresses:        orEmpty(ipStrings(cert.IPAddresses)),
+			EmailAddresses:     orEmpty(cert.EmailAddresses),
+			URIs:               orEmpty(urlStrings(cert.URIs)),
+			OtherNames:         orEmpty(otherNames(cert)),
+			NotBefore:          cert.NotBefore.UTC(),
+			NotAfter:           cert.NotAfter.UTC(),
+			SecondsUntilExpiry: int64(cert.NotAfter.Sub(now).Seconds()),
+			ValidityStatus:     string(tls.CheckValidity(cert, now).Status),
+			Issuer:             cert.Issuer.String(),
+			Serial:             cert.SerialNumber.String(),
+			SignatureAlgorithm: signatureAlgorithmName(cert),
+			PublicKey:          publicKeyOf(cert),
+			Fingerprints: Fingerprints{
+				SHA1:       sha1Fingerprint(cert),
+				SHA256:     sha256Fingerprint(cert),
+				SPKISHA256: spkiPin(cert),
+			},
+			KeyWarnings: keyWarnings(cert).all(),
+		})
+	}
+
+	return doc
+}
+
+// orEmpty returns s, or an empty slice when s is nil so it encodes as [].
+func orEmpty(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// PrintJSON writes doc to writer as indented JSON.
+func PrintJSON(writer io.Writer, doc Document) error {
+	return encodeJSON(writer, doc)
+}
+
+func encodeJSON(writer io.Writer, v any) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,216 @@
+package pretty
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func publicKeyOf(cert *x509.Certificate) PublicKey {
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return PublicKey{Algorithm: "RSA", Size: pub.N.BitLen(), Exponent: pub.E}
+	case *ecdsa.PublicKey:
+		params := pub.Curve.Params()
+		return PublicKey{Algorithm: "ECDSA", Size: params.BitSize, Curve: params.Name}
+	case ed25519.PublicKey:
+		return PublicKey{Algorithm: "Ed25519", Size: 256}
+	default:
+		return PublicKey{Algorithm: publicKeyAlgorithmName(cert)}
+	}
+}
+
+// algorithmNames names the algorithms that crypto/x509 does not know, or
+// knows without their parameter set, by OID.
+var algorithmNames = map[string]string{
+	"2.16.840.1.101.3.4.3.17": "ML-DSA-44",
+	"2.16.840.1.101.3.4.3.18": "ML-DSA-65",
+	"2.16.840.1.101.3.4.3.19": "ML-DSA-87",
+}
+
+// publicKeyAlgorithmName names the algorithm of a public key crypto/x509 did
+// not parse, by OID when crypto/x509 does not know it.
+func publicKeyAlgorithmName(cert *x509.Certificate) string {
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(cert.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return cert.PublicKeyAlgorithm.String()
+	}
+
+	if name, ok := algorithmNames[spki.Algorithm.Algorithm.String()]; ok {
+		return name
+	}
+	if cert.PublicKeyAlgorithm != x509.UnknownPublicKeyAlgorithm {
+		return cert.PublicKeyAlgorithm.String()
+	}
+	return spki.Algorithm.Algorithm.String()
+}
+
+// signatureAlgorithmName names the signature algorithm of cert as crypto/x509
+// does, or by OID when crypto/x509 does not know it.
+func signatureAlgorithmName(cert *x509.Certificate) string {
+	var certificate struct {
+		TBSCertificate     asn1.RawValue
+		SignatureAlgorithm pkix.AlgorithmIdentifier
+		Signature          asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(cert.Raw, &certificate); err != nil {
+		return cert.SignatureAlgorithm.String()
+	}
+
+	if name, ok := algorithmNames[certificate.SignatureAlgorithm.Algorithm.String()]; ok {
+		return name
+	}
+	if cert.SignatureAlgorithm != x509.UnknownSignatureAlgorithm {
+		return cert.SignatureAlgorithm.String()
+	}
+	return certificate.SignatureAlgorithm.Algorithm.String()
+}
+
+// minRSABits is the smallest RSA key the CA/Browser Forum allows.
+const minRSABits = 2048
+
+// keyFields describes the public key and signature of cert, with a warning
+// after any deprecated choice.
+func keyFields(cert *x509.Certificate) []field {
+	pub := publicKeyOf(cert)
+	warnings := keyWarnings(cert)
+
+	fields := []field{{key: "Algorithm", value: withWarning(pub.Algorithm, warnings.algorithm)}}
+	switch {
+	case pub.Curve != "":
+		fields = append(fields, field{key: "Curve", value: fmt.Sprintf("%s (%d bits)", pub.Curve, pub.Size)})
+	case pub.Size > 0:
+		fields = append(fields, field{key: "Size", value: withWarning(fmt.Sprintf("%d bits", pub.Size), warnings.size)})
+	}
+	if pub.Exponent != 0 {
+		fields = append(fields, field{key: "Exponent", value: withWarning(fmt.Sprintf("%d", pub.Exponent), warnings.exponent)})
+	}
+	fields = append(fields, field{key: "Signature", value: withWarning(niceSigAlg(cert), warnings.signature)})
+
+	return fields
+}
+
+// keyWarning explains what is wrong with each part of a certificate's key
+// and signature, empty when nothing is.
+type keyWarning struct {
+	algorithm string
+	size      string
+	exponent  string
+	signature string
+}
+
+func (w keyWarning) all() []string {
+	all := []string{}
+	for _, warning := range []string{w.algorithm, w.size, w.exponent, w.signature} {
+		if warning != "" {
+			all = append(all, warning)
+		}
+	}
+	return all
+}
+
+func keyWarnings(cert *x509.Certificate) keyWarning {
+	var w keyWarning
+	pub := publicKeyOf(cert)
+
+	if cert.PublicKeyAlgorithm == x509.DSA {
+		w.algorithm = "DSA is deprecated"
+	}
+	if pub.Algorithm == "RSA" && pub.Size < minRSABits {
+		w.size = fmt.Sprintf("below the %d bit minimum", minRSABits)
+	}
+	if pub.Algorithm == "RSA" && pub.Exponent < 65537 {
+		w.exponent = "smaller than the usual 65537"
+	}
+
+	// Clients never check the signature on a trusted root, so how a root
+	// signed itself does not matter.
+	if !(cert.IsCA && isSelfSigned(cert)) {
+		switch cert.SignatureAlgorithm {
+		case x509.MD2WithRSA, x509.MD5WithRSA:
+			w.signature = "MD5 and MD2 are broken"
+		case x509.SHA1WithRSA, x509.ECDSAWithSHA1, x509.DSAWithSHA1:
+			w.signature = "SHA-1 is deprecated"
+		case x509.DSAWithSHA256:
+			w.signature = "DSA is deprecated"
+		}
+	}
+
+	return w
+}
+
+func withWarning(value, warning string) string {
+	if warning == "" {
+		return value
+	}
+	return fmt.Sprintf("%s ⚠️ %s", value, warning)
+}
+
+func sha1Fingerprint(cert *x509.Certificate) string {
+	sum := sha1.Sum(cert.Raw)
+	return colonHex(sum[:])
+}
+
+func sha256Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return colonHex(sum[:])
+}
+
+// spkiPin is the base64 SHA-256 of the certificate's subject public key info,
+// which stays the same when a certificate is renewed with the same key.
+func spkiPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// fingerprintOf returns the fingerprint of cert, of the given kind.
+func fingerprintOf(cert *x509.Certificate, fingerprint Fingerprint) string {
+	switch fingerprint {
+	case FingerprintSHA1:
+		return sha1Fingerprint(cert)
+	case FingerprintSHA256:
+		return sha256Fingerprint(cert)
+	default:
+		return spkiPin(cert)
+	}
+}
+
+// fingerprintFields lists every fingerprint of cert.
+func fingerprintFields(cert *x509.Certificate) []field {
+	return []field{
+		{key: "SHA-1", value: sha1Fingerprint(cert)},
+		{key: "SHA-256", value: sha256Fingerprint(cert)},
+		{key: "SPKI Pin", value: "sha256/" + spkiPin(cert)},
+	}
+}
+
+// PrintFingerprints writes one fingerprint per certificate, one per line in
+// chain order, for scripts.
+func PrintFingerprints(writer io.Writer, certs []*x509.Certificate, fingerprint Fingerprint) error {
+	for _, cert := range certs {
+		if _, err := fmt.Fprintln(writer, fingerprintOf(cert, fingerprint)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func colonHex(b []byte) string {
+	parts := make([]string, len(b))
+	for i, v := range b {
+		parts[i] = fmt.Sprintf("%02X", v)
+	}
+	return strings.Join(parts, ":")
+}
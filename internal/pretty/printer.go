@@ -7,6 +7,8 @@ import (
 	"strings"
 	"text/tabwriter"
 	"time"
+
+	"github.com/kevholditch/tls/internal/tls"
 )
 
 type errorWriter struct {
@@ -21,6 +23,13 @@ func (ew *errorWriter) printKV(k, v string) {
 	_, ew.err = fmt.Fprintf(ew.w, "%s:\t%s\n", k, v)
 }
 
+func (ew *errorWriter) printLine(line string) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintln(ew.w, line)
+}
+
 func (ew *errorWriter) newLine() {
 	if ew.err != nil {
 		return
@@ -28,7 +37,7 @@ func (ew *errorWriter) newLine() {
 	_, ew.err = fmt.Fprintln(ew.w, "\t")
 }
 
-func formatDNS(names []string) string {
+func formatNames(names []string) string {
 	if len(names) == 0 {
 		return "[]"
 	}
@@ -54,25 +63,59 @@ func Print(writer io.Writer, cert *x509.Certificate, now time.Time) error {
 	ew.newLine()
 	ew.printKV("Common Name", cert.Subject.CommonName)
 	ew.printKV("Subject", cert.Subject.String())
-	ew.printKV("DNS Names", formatDNS(cert.DNSNames))
+
+	ew.newLine()
+	ew.printLine("Subject Alternative Names:")
+	for _, f := range sanFields(cert) {
+		ew.printKV(f.key, f.value)
+	}
 
 	ew.newLine()
 	ew.printKV("Not Before", cert.NotBefore.Format(time.RFC3339))
 	ew.printKV("Not After", cert.NotAfter.Format(time.RFC3339))
-	ew.printKV("Expires In", expiresIn(cert.NotAfter.Sub(now)))
+	ew.printKV("Expires In", expiresIn(tls.CheckValidity(cert, now)))
 
 	ew.newLine()
 	ew.printKV("Issuer", cert.Issuer.String())
 	ew.printKV("Serial", cert.SerialNumber.String())
 
+	// The key and extension names are long, so each section starts a new
+	// block rather than widening the columns above.
+	ew.newLine()
+	ew.printLine("Key:")
+	for _, f := range keyFields(cert) {
+		ew.printKV(f.key, f.value)
+	}
+
+	ew.newLine()
+	ew.printLine("Fingerprints:")
+	for _, f := range fingerprintFields(cert) {
+		ew.printKV(f.key, f.value)
+	}
+
+	if fields := extensionFields(cert); len(fields) > 0 {
+		ew.newLine()
+		ew.printLine("Extensions:")
+		for _, f := range fields {
+			ew.printKV(f.key, f.value)
+		}
+	}
+
 	if ew.err != nil {
 		return ew.err
 	}
 	return w.Flush()
 }
 
-func expiresIn(expiresIn time.Duration) string {
-	totalHours := int(expiresIn.Hours())
+func expiresIn(validity tls.Validity) string {
+	switch validity.Status {
+	case tls.StatusExpired:
+		return fmt.Sprintf("❌ Expired %s ago", tls.HumanDuration(validity.Remaining))
+	case tls.StatusNotYetValid:
+		return fmt.Sprintf("⏳ Valid from in %s", tls.HumanDuration(validity.StartsIn))
+	}
+
+	totalHours := int(validity.Remaining.Hours())
 	days := totalHours / 24
 	hours := totalHours % 24
 
@@ -88,8 +131,8 @@ func expiresIn(expiresIn time.Duration) string {
 	return fmt.Sprintf("%s %d Days %d Hours", sign, days, hours)
 }
 
-func niceSigAlg(sa x509.SignatureAlgorithm) string {
-	switch sa {
+func niceSigAlg(cert *x509.Certificate) string {
+	switch cert.SignatureAlgorithm {
 	case x509.SHA256WithRSA, x509.SHA256WithRSAPSS:
 		return "RSA-SHA256"
 	case x509.SHA384WithRSA, x509.SHA384WithRSAPSS:
@@ -98,6 +141,8 @@ func niceSigAlg(sa x509.SignatureAlgorithm) string {
 		return "RSA-SHA512"
 	case x509.SHA1WithRSA:
 		return "RSA-SHA1"
+	case x509.ECDSAWithSHA1:
+		return "ECDSA-SHA1"
 	case x509.ECDSAWithSHA256:
 		return "ECDSA-SHA256"
 	case x509.ECDSAWithSHA384:
@@ -107,6 +152,6 @@ func niceSigAlg(sa x509.SignatureAlgorithm) string {
 	case x509.PureEd25519:
 		return "Ed25519"
 	default:
-		return sa.String()
+		return signatureAlgorithmName(cert)
 	}
 }
@@ -0,0 +1,117 @@
+package pretty
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"unicode/utf8"
+)
+
+var oidSubjectAltName = asn1.ObjectIdentifier{2, 5, 29, 17}
+
+// otherNameTypes names the otherName types seen in practice: Microsoft user
+// principal names and the types defined by the PKIX RFCs.
+var otherNameTypes = map[string]string{
+	"1.3.6.1.4.1.311.20.2.3": "UPN",
+	"1.3.6.1.5.5.7.8.9":      "SmtpUTF8Mailbox",
+	"1.3.6.1.5.5.7.8.4":      "Permanent Identifier",
+	"1.3.6.1.5.5.7.8.7":      "SRV Name",
+}
+
+// otherNameTag is the implicit context specific tag of an otherName in a
+// GeneralName.
+const otherNameTag = 0
+
+// sanFields lists the subject alternative names of cert grouped by type.
+// Every type is listed, empty or not, so the section always lines up the
+// same way as the names above it: "Other Names" is as long as "Common Name".
+func sanFields(cert *x509.Certificate) []field {
+	return []field{
+		{key: "DNS Names", value: formatNames(cert.DNSNames)},
+		{key: "IPs", value: formatNames(ipStrings(cert.IPAddresses))},
+		{key: "Emails", value: formatNames(cert.EmailAddresses)},
+		{key: "URIs", value: formatNames(urlStrings(cert.URIs))},
+		{key: "Other Names", value: formatNames(otherNames(cert))},
+	}
+}
+
+func ipStrings(ips []net.IP) []string {
+	var s []string
+	for _, ip := range ips {
+		s = append(s, ip.String())
+	}
+	return s
+}
+
+func urlStrings(urls []*url.URL) []string {
+	var s []string
+	for _, u := range urls {
+		s = append(s, u.String())
+	}
+	return s
+}
+
+// otherNames describes the otherName entries in cert's subject alternative
+// names, which crypto/x509 skips. Each one is "type: value", with the type
+// named when it is known and the value as hex when it is not a string.
+func otherNames(cert *x509.Certificate) []string {
+	var names []string
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidSubjectAltName) {
+			continue
+		}
+
+		var generalNames []asn1.RawValue
+		if _, err := asn1.Unmarshal(ext.Value, &generalNames); err != nil {
+			return nil
+		}
+		for _, name := range generalNames {
+			if name.Class == asn1.ClassContextSpecific && name.Tag == otherNameTag {
+				names = append(names, formatOtherName(name.FullBytes))
+			}
+		}
+	}
+	return names
+}
+
+// otherName is an otherName GeneralName, RFC 5280 section 4.2.1.6. Value is
+// the explicit [0] wrapping the value, which encoding/asn1 leaves in place
+// for a RawValue.
+type otherName struct {
+	TypeID asn1.ObjectIdentifier
+	Value  asn1.RawValue
+}
+
+func formatOtherName(der []byte) string {
+	var on otherName
+	if _, err := asn1.UnmarshalWithParams(der, &on, "tag:0"); err != nil {
+		return "malformed otherName"
+	}
+
+	typeName := on.TypeID.String()
+	if name, ok := otherNameTypes[typeName]; ok {
+		typeName = fmt.Sprintf("%s (%s)", name, on.TypeID)
+	}
+	return typeName + ": " + otherNameValue(on.Value)
+}
+
+// otherNameValue returns the value inside wrapper as text when it is one of
+// the string types, and as hex of its DER otherwise.
+func otherNameValue(wrapper asn1.RawValue) string {
+	var value asn1.RawValue
+	if _, err := asn1.Unmarshal(wrapper.Bytes, &value); err != nil {
+		return hex.EncodeToString(wrapper.Bytes)
+	}
+	if value.Class == asn1.ClassUniversal {
+		switch value.Tag {
+		case asn1.TagUTF8String, asn1.TagIA5String, asn1.TagPrintableString:
+			if utf8.Valid(value.Bytes) {
+				return string(value.Bytes)
+			}
+		}
+	}
+	return hex.EncodeToString(value.FullBytes)
+}
@@ -0,0 +1,182 @@
+package pretty
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kevholditch/tls/internal/tls"
+)
+
+// VersionScan is the outcome of tls.ScanVersions, written as JSON by
+// PrintVersionScanJSON. Like Document, fields are only ever added.
+type VersionScan struct {
+	Target   string    `json:"target"`
+	Versions []Version `json:"versions"`
+}
+
+// Version is whether the server accepted one protocol version.
+type Version struct {
+	Version  string `json:"version"`
+	Accepted bool   `json:"accepted"`
+	// Deprecated is true for TLS 1.0 and 1.1, which RFC 8996 forbids.
+	Deprecated bool `json:"deprecated"`
+	// Error is why the handshake failed when the version was not accepted.
+	Error string `json:"error,omitempty"`
+}
+
+// NewVersionScan builds a VersionScan from the result of tls.ScanVersions.
+func NewVersionScan(target string, results []tls.VersionResult) VersionScan {
+	scan := VersionScan{Target: target, Versions: make([]Version, 0, len(results))}
+	for _, r := range results {
+		v := Version{Version: r.Name, Accepted: r.Accepted, Deprecated: r.Deprecated}
+		if r.Err != nil {
+			v.Error = r.Err.Error()
+		}
+		scan.Versions = append(scan.Versions, v)
+	}
+	return scan
+}
+
+// PrintVersionScan prints which protocol versions the server accepted,
+// warning about deprecated ones.
+func PrintVersionScan(writer io.Writer, scan VersionScan) error {
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+
+	ew := &errorWriter{w: w}
+	ew.printLine("Protocol versions for " + scan.Target + ":")
+	for _, v := range scan.Versions {
+		ew.printKV(v.Version, formatVersion(v))
+	}
+
+	if ew.err != nil {
+		return ew.err
+	}
+	return w.Flush()
+}
+
+func PrintVersionScanJSON(writer io.Writer, scan VersionScan) error {
+	return encodeJSON(writer, scan)
+}
+
+func formatVersion(v Version) string {
+	switch {
+	case v.Accepted && v.Deprecated:
+		return "⚠️ accepted (deprecated by RFC 8996)"
+	case v.Accepted:
+		return "✅ accepted"
+	case v.Deprecated:
+		return "✅ rejected"
+	default:
+		return "❌ rejected"
+	}
+}
+
+// CipherScan is the outcome of tls.ScanCiphers, written as JSON by
+// PrintCipherScanJSON. Like Document, fields are only ever added.
+type CipherScan struct {
+	Target   string           `json:"target"`
+	Versions []VersionCiphers `json:"versions"`
+}
+
+// VersionCiphers are the cipher suites the server accepted for one protocol
+// version.
+type VersionCiphers struct {
+	Version  string `json:"version"`
+	Accepted bool   `json:"accepted"`
+	// ServerPreference is whether the server enforces its own order, null
+	// when it accepted fewer than two suites or the order could not be
+	// tested.
+	ServerPreference *bool         `json:"server_preference"`
+	CipherSuites     []CipherSuite `json:"cipher_suites"`
+}
+
+// CipherSuite is a cipher suite the server accepted.
+type CipherSuite struct {
+	Name string `json:"name"`
+	// ID is the suite's IANA value in hex, e.g. "0xC02F".
+	ID string `json:"id"`
+	// Grade is "strong", "weak" or "insecure".
+	Grade      string   `json:"grade"`
+	Weaknesses []string `json:"weaknesses"`
+}
+
+// NewCipherScan builds a CipherScan from the result of tls.ScanCiphers.
+func NewCipherScan(target string, results []tls.VersionCiphers) CipherScan {
+	scan := CipherScan{Target: target, Versions: make([]VersionCiphers, 0, len(results))}
+	for _, r := range results {
+		v := VersionCiphers{
+			Version:      r.Name,
+			Accepted:     r.Accepted,
+			CipherSuites: make([]CipherSuite, 0, len(r.Ciphers)),
+		}
+		if r.PreferenceKnown {
+			serverPreference := r.ServerPreference
+			v.ServerPreference = &serverPreference
+		}
+		for _, c := range r.Ciphers {
+			weaknesses := c.Weaknesses
+			if weaknesses == nil {
+				weaknesses = []string{}
+			}
+			v.CipherSuites = append(v.CipherSuites, CipherSuite{
+				Name:       c.Name,
+				ID:         fmt.Sprintf("0x%04X", c.ID),
+				Grade:      string(c.Grade),
+				Weaknesses: weaknesses,
+			})
+		}
+		scan.Versions = append(scan.Versions, v)
+	}
+	return scan
+}
+
+// PrintCipherScan prints the cipher suites the server accepted for each
+// protocol version, most preferred first when the server has a preference,
+// grading each one.
+func PrintCipherScan(writer io.Writer, scan CipherScan) error {
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+
+	ew := &errorWriter{w: w}
+	ew.printLine("Cipher suites for " + scan.Target + ":")
+	for _, v := range scan.Versions {
+		ew.printKV(v.Version, formatCipherOrder(v))
+		for _, c := range v.CipherSuites {
+			ew.printLine("  " + formatCipherSuite(c))
+		}
+	}
+
+	if ew.err != nil {
+		return ew.err
+	}
+	return w.Flush()
+}
+
+func PrintCipherScanJSON(writer io.Writer, scan CipherScan) error {
+	return encodeJSON(writer, scan)
+}
+
+func formatCipherOrder(v VersionCiphers) string {
+	switch {
+	case !v.Accepted:
+		return "not accepted"
+	case v.ServerPreference == nil:
+		return "accepted"
+	case *v.ServerPreference:
+		return "server preference order"
+	default:
+		return "client preference order"
+	}
+}
+
+func formatCipherSuite(c CipherSuite) string {
+	switch c.Grade {
+	case string(tls.GradeInsecure):
+		return fmt.Sprintf("❌ %s (insecure: %s)", c.Name, strings.Join(c.Weaknesses, ", "))
+	case string(tls.GradeWeak):
+		return fmt.Sprintf("⚠️ %s (weak: %s)", c.Name, strings.Join(c.Weaknesses, ", "))
+	default:
+		return "✅ " + c.Name
+	}
+}
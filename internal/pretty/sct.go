@@ -0,0 +1,84 @@
+package pretty
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kevholditch/tls/internal/tls"
+)
+
+// SCT describes a signed certificate timestamp.
+type SCT struct {
+	// Source is "certificate" for SCTs embedded in the leaf certificate and
+	// "handshake" for SCTs the server sent in the TLS extension.
+	Source string `json:"source"`
+	// LogID is the base64 log ID, as in the log lists.
+	LogID string `json:"log_id"`
+	// LogName is the log's description from the log list, empty when the
+	// log is not in it or no log list was given.
+	LogName   string    `json:"log_name,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	// SignatureAlgorithm is the log's signature, e.g. "ECDSA-SHA256".
+	SignatureAlgorithm string `json:"signature_algorithm"`
+}
+
+var sctHashNames = map[uint8]string{1: "MD5", 2: "SHA1", 3: "SHA224", 4: "SHA256", 5: "SHA384", 6: "SHA512"}
+var sctSignatureNames = map[uint8]string{1: "RSA", 2: "DSA", 3: "ECDSA"}
+
+// NewSCTs builds the SCTs describing scts, naming their logs from logs,
+// which may be nil.
+func NewSCTs(scts []tls.SCT, logs tls.LogList) []SCT {
+	descriptions := make([]SCT, 0, len(scts))
+	for _, sct := range scts {
+		descriptions = append(descriptions, SCT{
+			Source:             string(sct.Source),
+			LogID:              base64.StdEncoding.EncodeToString(sct.LogID[:]),
+			LogName:            logs[sct.LogID],
+			Timestamp:          sct.Timestamp,
+			SignatureAlgorithm: sctSignatureAlgorithm(sct.HashAlgorithm, sct.SignatureAlgorithm),
+		})
+	}
+	return descriptions
+}
+
+func sctSignatureAlgorithm(hash, signature uint8) string {
+	hashName, hashOK := sctHashNames[hash]
+	signatureName, signatureOK := sctSignatureNames[signature]
+	if !hashOK || !signatureOK {
+		return fmt.Sprintf("unknown (hash %d, signature %d)", hash, signature)
+	}
+	return signatureName + "-" + hashName
+}
+
+// PrintSCTs prints the signed certificate timestamps of the leaf, or that
+// there are none.
+func PrintSCTs(writer io.Writer, scts []SCT) error {
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+
+	ew := &errorWriter{w: w}
+	ew.newLine()
+	ew.printLine("Certificate Transparency:")
+	if len(scts) == 0 {
+		ew.printKV("SCTs", "none")
+	}
+	for i, sct := range scts {
+		if i > 0 {
+			ew.newLine()
+		}
+		if sct.LogName != "" {
+			ew.printKV("Log", sct.LogName)
+		}
+		ew.printKV("Log ID", sct.LogID)
+		ew.printKV("Timestamp", sct.Timestamp.Format(time.RFC3339))
+		ew.printKV("Signature", sct.SignatureAlgorithm)
+		ew.printKV("Source", sct.Source)
+	}
+
+	if ew.err != nil {
+		return ew.err
+	}
+	return w.Flush()
+}
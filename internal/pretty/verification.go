@@ -0,0 +1,102 @@
+package pretty
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kevholditch/tls/internal/tls"
+)
+
+// Verification is the outcome of verifying a chain against trusted roots.
+type Verification struct {
+	Trusted bool `json:"trusted"`
+	// Error is the x509 error explaining why the chain is untrusted.
+	Error string `json:"error,omitempty"`
+	// Chain lists the subject common names of the verified chain, from the
+	// leaf to the trusted root.
+	Chain []string `json:"chain,omitempty"`
+	// Hostname is the result of checking the leaf against the server name,
+	// absent when there was no name to check.
+	Hostname *Hostname `json:"hostname,omitempty"`
+}
+
+// Hostname describes whether the leaf certificate covers a host.
+type Hostname struct {
+	Host  string `json:"host"`
+	Valid bool   `json:"valid"`
+	// Error is the x509 error explaining why the host is not covered.
+	Error string `json:"error,omitempty"`
+	// Matched is the SAN entry that covers Host.
+	Matched  string `json:"matched,omitempty"`
+	Wildcard bool   `json:"wildcard"`
+	// CommonNameOnly is true when Host only appears in the legacy Common
+	// Name, which modern clients reject.
+	CommonNameOnly bool `json:"common_name_only"`
+}
+
+// NewHostname builds a Hostname from the result of tls.MatchHostname.
+func NewHostname(m tls.HostnameMatch) *Hostname {
+	h := &Hostname{
+		Host:           m.Host,
+		Valid:          m.Err == nil,
+		Matched:        m.Matched,
+		Wildcard:       m.Wildcard,
+		CommonNameOnly: m.CommonNameOnly,
+	}
+	if m.Err != nil {
+		h.Error = m.Err.Error()
+	}
+	return h
+}
+
+// NewVerification builds a Verification from the result of tls.Verify.
+func NewVerification(chain []*x509.Certificate, err error) Verification {
+	if err != nil {
+		return Verification{Error: err.Error()}
+	}
+
+	v := Verification{Trusted: true}
+	for _, cert := range chain {
+		v.Chain = append(v.Chain, displayName(cert.Subject))
+	}
+	return v
+}
+
+// PrintVerification prints whether the chain is trusted and, if it is, the
+// path that was verified.
+func PrintVerification(writer io.Writer, v Verification) error {
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+
+	ew := &errorWriter{w: w}
+	ew.newLine()
+	if v.Trusted {
+		ew.printKV("Verification", "✅ trusted")
+		ew.printKV("Trusted Chain", strings.Join(v.Chain, " → "))
+	} else {
+		ew.printKV("Verification", "❌ untrusted: "+v.Error)
+	}
+	if v.Hostname != nil {
+		ew.printKV("Hostname", formatHostname(v.Hostname))
+	}
+
+	if ew.err != nil {
+		return ew.err
+	}
+	return w.Flush()
+}
+
+func formatHostname(h *Hostname) string {
+	switch {
+	case h.Valid && h.Wildcard:
+		return fmt.Sprintf("✅ %s matched %s (wildcard)", h.Host, h.Matched)
+	case h.Valid:
+		return fmt.Sprintf("✅ %s matched %s", h.Host, h.Matched)
+	case h.CommonNameOnly:
+		return fmt.Sprintf("❌ %s is only in the Common Name, which modern clients reject without a matching SAN", h.Host)
+	default:
+		return fmt.Sprintf("❌ %s: %s", h.Host, h.Error)
+	}
+}
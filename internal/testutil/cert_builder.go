@@ -1,6 +1,7 @@
 package testutil
 
 import (
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
@@ -153,7 +154,7 @@ func (cb *CertBuilder) BuildCert() *x509.Certificate {
 	return cb.cert
 }
 
-// Build returns the built certificate
+// Build returns the built certificate, self-signed
 func (cb *CertBuilder) Build() tls.Certificate {
 
 	// Generate test certificate
@@ -162,7 +163,26 @@ func (cb *CertBuilder) Build() tls.Certificate {
 		panic(err)
 	}
 
-	derBytes, err := x509.CreateCertificate(rand.Reader, cb.cert, cb.cert, &priv.PublicKey, priv)
+	return cb.sign(cb.cert, priv, priv)
+}
+
+// BuildSignedBy returns the built certificate signed by the given issuer
+func (cb *CertBuilder) BuildSignedBy(issuer tls.Certificate) tls.Certificate {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	return cb.sign(issuer.Leaf, priv, issuer.PrivateKey)
+}
+
+func (cb *CertBuilder) sign(parent *x509.Certificate, priv *rsa.PrivateKey, signer crypto.PrivateKey) tls.Certificate {
+	derBytes, err := x509.CreateCertificate(rand.Reader, cb.cert, parent, &priv.PublicKey, signer)
+	if err != nil {
+		panic(err)
+	}
+
+	leaf, err := x509.ParseCertificate(derBytes)
 	if err != nil {
 		panic(err)
 	}
@@ -170,5 +190,16 @@ func (cb *CertBuilder) Build() tls.Certificate {
 	return tls.Certificate{
 		Certificate: [][]byte{derBytes},
 		PrivateKey:  priv,
+		Leaf:        leaf,
+	}
+}
+
+// Chain returns a certificate that presents the leaf followed by the given issuers
+func Chain(leaf tls.Certificate, issuers ...tls.Certificate) tls.Certificate {
+	chain := leaf
+	chain.Certificate = append([][]byte{}, leaf.Certificate...)
+	for _, issuer := range issuers {
+		chain.Certificate = append(chain.Certificate, issuer.Certificate...)
 	}
+	return chain
 }
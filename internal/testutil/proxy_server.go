@@ -0,0 +1,230 @@
+package testutil
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// ProxyServer is a stand-in HTTP CONNECT or SOCKS5 proxy that tunnels to
+// whatever address it is asked for and records the addresses it tunnelled to.
+type ProxyServer struct {
+	protocol string
+	username string
+	password string
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	tunnels []string
+}
+
+// NewProxyServer creates a new proxy test server for protocol, either http or
+// socks5
+func NewProxyServer(protocol string) (*ProxyServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	return &ProxyServer{protocol: protocol, listener: listener}, nil
+}
+
+// WithCredentials makes the proxy require a username and password
+func (s *ProxyServer) WithCredentials(username, password string) *ProxyServer {
+	s.username = username
+	s.password = password
+	return s
+}
+
+func (s *ProxyServer) GetAddress() string {
+	return s.listener.Addr().String()
+}
+
+// URL returns the URL to configure a client with, without credentials
+func (s *ProxyServer) URL() string {
+	return s.protocol + "://" + s.GetAddress()
+}
+
+// Tunnels returns every address the proxy has opened a tunnel to
+func (s *ProxyServer) Tunnels() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.tunnels...)
+}
+
+// Start serves connections until the server is stopped
+func (s *ProxyServer) Start(ready chan<- struct{}) error {
+	ready <- struct{}{}
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.serve(conn)
+		}()
+	}
+}
+
+// Stop stops the server and waits for open tunnels to finish
+func (s *ProxyServer) Stop() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *ProxyServer) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+
+	var addr string
+	switch s.protocol {
+	case "http":
+		addr = s.acceptHTTP(conn, r)
+	case "socks5":
+		addr = s.acceptSOCKS5(conn, r)
+	}
+	if addr == "" {
+		return
+	}
+
+	target, err := net.Dial("tcp", addr)
+	if err != nil {
+		return
+	}
+	defer target.Close()
+
+	s.mu.Lock()
+	s.tunnels = append(s.tunnels, addr)
+	s.mu.Unlock()
+
+	if s.protocol == "http" {
+		_, _ = fmt.Fprint(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	} else {
+		_, _ = conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+	}
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(target, r)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(conn, target)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+func (s *ProxyServer) acceptHTTP(conn net.Conn, r *bufio.Reader) string {
+	req, err := http.ReadRequest(r)
+	if err != nil || req.Method != http.MethodConnect {
+		return ""
+	}
+
+	if s.username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(s.username + ":" + s.password))
+		if req.Header.Get("Proxy-Authorization") != "Basic "+credentials {
+			_, _ = fmt.Fprint(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+			return ""
+		}
+	}
+	return req.Host
+}
+
+func (s *ProxyServer) acceptSOCKS5(conn net.Conn, r *bufio.Reader) string {
+	greeting := make([]byte, 2)
+	if _, err := io.ReadFull(r, greeting); err != nil {
+		return ""
+	}
+	methods := make([]byte, greeting[1])
+	if _, err := io.ReadFull(r, methods); err != nil {
+		return ""
+	}
+
+	if s.username == "" {
+		_, _ = conn.Write([]byte{0x05, 0x00})
+	} else {
+		_, _ = conn.Write([]byte{0x05, 0x02})
+		if !s.authenticateSOCKS5(conn, r) {
+			return ""
+		}
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return ""
+	}
+
+	var host string
+	switch header[3] {
+	case 0x01, 0x04:
+		ip := make([]byte, 4)
+		if header[3] == 0x04 {
+			ip = make([]byte, 16)
+		}
+		if _, err := io.ReadFull(r, ip); err != nil {
+			return ""
+		}
+		host = net.IP(ip).String()
+	case 0x03:
+		length, err := r.ReadByte()
+		if err != nil {
+			return ""
+		}
+		name := make([]byte, length)
+		if _, err := io.ReadFull(r, name); err != nil {
+			return ""
+		}
+		host = string(name)
+	default:
+		return ""
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(r, port); err != nil {
+		return ""
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+}
+
+func (s *ProxyServer) authenticateSOCKS5(conn net.Conn, r *bufio.Reader) bool {
+	readField := func() string {
+		length, err := r.ReadByte()
+		if err != nil {
+			return ""
+		}
+		field := make([]byte, length)
+		if _, err := io.ReadFull(r, field); err != nil {
+			return ""
+		}
+		return string(field)
+	}
+
+	if _, err := r.ReadByte(); err != nil {
+		return false
+	}
+	username, password := readField(), readField()
+
+	if username != s.username || password != s.password {
+		_, _ = conn.Write([]byte{0x01, 0x01})
+		return false
+	}
+	_, _ = conn.Write([]byte{0x01, 0x00})
+	return true
+}
@@ -0,0 +1,319 @@
+package testutil
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+)
+
+// StartTLSServer is a fake server that speaks just enough of a plaintext
+// protocol to negotiate an upgrade to TLS, then completes the handshake.
+type StartTLSServer struct {
+	protocol  string
+	tlsConfig *tls.Config
+	refuse    bool
+	listener  net.Listener
+	wg        sync.WaitGroup
+}
+
+// NewStartTLSServer creates a new STARTTLS test server for protocol, one of
+// smtp, imap, pop3, ftp, postgres, mysql, ldap, xmpp or generic. The generic
+// server greets, expects a "STARTTLS" line and replies "OK"
+func NewStartTLSServer(protocol string, buildTlsConfig func(b *TlsConfigBuilder) *tls.Config) (*StartTLSServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	return &StartTLSServer{
+		protocol:  protocol,
+		tlsConfig: buildTlsConfig(NewTlsConfigBuilder()),
+		listener:  listener,
+	}, nil
+}
+
+// Refuse makes the server reject the upgrade instead of accepting it
+func (s *StartTLSServer) Refuse() *StartTLSServer {
+	s.refuse = true
+	return s
+}
+
+func (s *StartTLSServer) GetAddress() string {
+	return s.listener.Addr().String()
+}
+
+// Start serves connections until the server is stopped
+func (s *StartTLSServer) Start(ready chan<- struct{}) error {
+	ready <- struct{}{}
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.serve(conn)
+		}()
+	}
+}
+
+// Stop stops the server and waits for open connections to finish
+func (s *StartTLSServer) Stop() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *StartTLSServer) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+
+	var upgrade bool
+	switch s.protocol {
+	case "smtp":
+		upgrade = s.serveSMTP(conn, r)
+	case "imap":
+		upgrade = s.serveIMAP(conn, r)
+	case "pop3":
+		upgrade = s.servePOP3(conn, r)
+	case "ftp":
+		upgrade = s.serveFTP(conn, r)
+	case "postgres":
+		upgrade = s.servePostgres(conn, r)
+	case "mysql":
+		upgrade = s.serveMySQL(conn, r)
+	case "ldap":
+		upgrade = s.serveLDAP(conn, r)
+	case "xmpp":
+		upgrade = s.serveXMPP(conn, r)
+	case "generic":
+		upgrade = s.serveGeneric(conn, r)
+	}
+
+	if upgrade {
+		_ = tls.Server(&bufferedConn{Conn: conn, r: r}, s.tlsConfig).Handshake()
+	}
+}
+
+// bufferedConn reads through r so the handshake sees any bytes that were read
+// ahead while parsing the plaintext protocol.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+func (s *StartTLSServer) serveSMTP(conn net.Conn, r *bufio.Reader) bool {
+	writeLines(conn, "220-mail.example.com ESMTP test server", "220 ready")
+	if !expectLine(r, "EHLO ") {
+		return false
+	}
+
+	if s.refuse {
+		writeLines(conn, "250-mail.example.com", "250 8BITMIME")
+		return false
+	}
+	writeLines(conn, "250-mail.example.com", "250-8BITMIME", "250 STARTTLS")
+	if !expectLine(r, "STARTTLS") {
+		return false
+	}
+	writeLines(conn, "220 go ahead")
+	return true
+}
+
+func (s *StartTLSServer) serveIMAP(conn net.Conn, r *bufio.Reader) bool {
+	writeLines(conn, "* OK [CAPABILITY IMAP4rev1 STARTTLS] test server ready")
+	if !expectLine(r, "a001 STARTTLS") {
+		return false
+	}
+
+	if s.refuse {
+		writeLines(conn, "a001 BAD STARTTLS not available")
+		return false
+	}
+	writeLines(conn, "* CAPABILITY IMAP4rev1", "a001 OK begin TLS negotiation now")
+	return true
+}
+
+func (s *StartTLSServer) servePOP3(conn net.Conn, r *bufio.Reader) bool {
+	writeLines(conn, "+OK test server ready")
+	if !expectLine(r, "STLS") {
+		return false
+	}
+
+	if s.refuse {
+		writeLines(conn, "-ERR STLS not available")
+		return false
+	}
+	writeLines(conn, "+OK begin TLS negotiation")
+	return true
+}
+
+func (s *StartTLSServer) serveFTP(conn net.Conn, r *bufio.Reader) bool {
+	writeLines(conn, "220 test server ready")
+	if !expectLine(r, "AUTH TLS") {
+		return false
+	}
+
+	if s.refuse {
+		writeLines(conn, "502 AUTH TLS not supported")
+		return false
+	}
+	writeLines(conn, "234 AUTH TLS successful")
+	return true
+}
+
+func (s *StartTLSServer) servePostgres(conn net.Conn, r *bufio.Reader) bool {
+	request := make([]byte, 8)
+	if _, err := io.ReadFull(r, request); err != nil {
+		return false
+	}
+	if binary.BigEndian.Uint32(request[4:8]) != 80877103 {
+		return false
+	}
+
+	if s.refuse {
+		_, _ = conn.Write([]byte("N"))
+		return false
+	}
+	_, _ = conn.Write([]byte("S"))
+	return true
+}
+
+func (s *StartTLSServer) serveMySQL(conn net.Conn, r *bufio.Reader) bool {
+	capabilities := uint32(0x00000200 | 0x00008000)
+	if !s.refuse {
+		capabilities |= 0x00000800
+	}
+
+	var payload []byte
+	payload = append(payload, 10)
+	payload = append(payload, "8.0.0-test\x00"...)
+	payload = binary.LittleEndian.AppendUint32(payload, 1)
+	payload = append(payload, "abcdefgh"...)
+	payload = append(payload, 0)
+	payload = binary.LittleEndian.AppendUint16(payload, uint16(capabilities))
+	payload = append(payload, 45)
+	payload = binary.LittleEndian.AppendUint16(payload, 2)
+	payload = binary.LittleEndian.AppendUint16(payload, uint16(capabilities>>16))
+	payload = append(payload, 21)
+	payload = append(payload, make([]byte, 10)...)
+	payload = append(payload, "ijklmnopqrst\x00"...)
+	payload = append(payload, "mysql_native_password\x00"...)
+
+	header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), 0}
+	_, _ = conn.Write(append(header, payload...))
+
+	if s.refuse {
+		return false
+	}
+
+	request := make([]byte, 4+32)
+	if _, err := io.ReadFull(r, request); err != nil {
+		return false
+	}
+	return binary.LittleEndian.Uint32(request[4:8])&0x00000800 != 0
+}
+
+func (s *StartTLSServer) serveLDAP(conn net.Conn, r *bufio.Reader) bool {
+	// The whole StartTLS request is short enough for single byte lengths.
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return false
+	}
+	request := make([]byte, header[1])
+	if _, err := io.ReadFull(r, request); err != nil {
+		return false
+	}
+	if !bytes.Contains(request, []byte("1.3.6.1.4.1.1466.20037")) {
+		return false
+	}
+
+	resultCode, diagnostic := byte(0), ""
+	if s.refuse {
+		resultCode, diagnostic = 2, "unsupported"
+	}
+
+	op := append([]byte{0x0a, 0x01, resultCode, 0x04, 0x00, 0x04, byte(len(diagnostic))}, diagnostic...)
+	message := append([]byte{0x02, 0x01, 0x01, 0x78, byte(len(op))}, op...)
+	_, _ = conn.Write(append([]byte{0x30, byte(len(message))}, message...))
+
+	return !s.refuse
+}
+
+func (s *StartTLSServer) serveXMPP(conn net.Conn, r *bufio.Reader) bool {
+	if !readUntil(r, "version='1.0'>") {
+		return false
+	}
+
+	features := "<starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'><required/></starttls>"
+	if s.refuse {
+		features = "<mechanisms xmlns='urn:ietf:params:xml:ns:xmpp-sasl'><mechanism>PLAIN</mechanism></mechanisms>"
+	}
+	_, _ = fmt.Fprintf(conn, "<?xml version='1.0'?><stream:stream from='example.com' id='1' "+
+		"xmlns='jabber:client' xmlns:stream='http://etherx.jabber.org/streams' version='1.0'>"+
+		"<stream:features>%s</stream:features>", features)
+	if s.refuse {
+		return false
+	}
+
+	if !readUntil(r, "/>") {
+		return false
+	}
+	_, _ = fmt.Fprint(conn, "<proceed xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>")
+	return true
+}
+
+func (s *StartTLSServer) serveGeneric(conn net.Conn, r *bufio.Reader) bool {
+	writeLines(conn, "HELLO test server")
+	if !expectLine(r, "STARTTLS") {
+		return false
+	}
+
+	if s.refuse {
+		writeLines(conn, "NO")
+		return false
+	}
+	writeLines(conn, "OK go ahead")
+	return true
+}
+
+// readUntil reads from r until what has been read ends with suffix
+func readUntil(r *bufio.Reader, suffix string) bool {
+	var read []byte
+	for !bytes.HasSuffix(read, []byte(suffix)) {
+		b, err := r.ReadByte()
+		if err != nil {
+			return false
+		}
+		read = append(read, b)
+	}
+	return true
+}
+
+func writeLines(conn net.Conn, lines ...string) {
+	for _, line := range lines {
+		_, _ = fmt.Fprintf(conn, "%s\r\n", line)
+	}
+}
+
+func expectLine(r *bufio.Reader, prefix string) bool {
+	line, err := r.ReadString('\n')
+	return err == nil && strings.HasPrefix(line, prefix)
+}
@@ -33,6 +33,16 @@ func (tcb *TlsConfigBuilder) WithMaximumTLSVersion(version uint16) *TlsConfigBui
 	return tcb
 }
 
+func (tcb *TlsConfigBuilder) WithCipherSuites(suites ...uint16) *TlsConfigBuilder {
+	tcb.tlsConfig.CipherSuites = suites
+	return tcb
+}
+
+func (tcb *TlsConfigBuilder) WithNextProtos(protos ...string) *TlsConfigBuilder {
+	tcb.tlsConfig.NextProtos = protos
+	return tcb
+}
+
 func (tcb *TlsConfigBuilder) Build() *tls.Config {
 	return tcb.tlsConfig
 }
@@ -2,6 +2,9 @@ package tls
 
 import (
 	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
 	"strconv"
 	"strings"
 )
@@ -22,21 +25,163 @@ func (e *ErrInvalidHost) Error() string {
 	return fmt.Sprintf("invalid host: %s", e.Host)
 }
 
+type ErrUnsupportedScheme struct {
+	Scheme string
+}
+
+func NewErrUnsupportedScheme(scheme string) *ErrUnsupportedScheme {
+	return &ErrUnsupportedScheme{
+		Scheme: scheme,
+	}
+}
+
+func (e *ErrUnsupportedScheme) Error() string {
+	return fmt.Sprintf("unsupported scheme: %s", e.Scheme)
+}
+
+// schemePorts maps the URL schemes a target may use to their default port.
+var schemePorts = map[string]int{
+	"http":  80,
+	"https": 443,
+	"wss":   443,
+	"ftps":  990,
+	"imaps": 993,
+	"ldaps": 636,
+	"pop3s": 995,
+	"smtps": 465,
+	"ircs":  6697,
+	// Plaintext protocols, upgraded with STARTTLS on their default port.
+	"ftp":        21,
+	"mysql":      3306,
+	"postgres":   5432,
+	"postgresql": 5432,
+	"imap":       143,
+	"pop3":       110,
+	"smtp":       25,
+	"submission": 587,
+	"ldap":       389,
+	"xmpp":       5222,
+}
+
+// schemeStartTLS maps the plaintext URL schemes to the protocol that upgrades
+// them, whatever port the URL gives.
+var schemeStartTLS = map[string]StartTLS{
+	"ftp":        StartTLSFTP,
+	"mysql":      StartTLSMySQL,
+	"postgres":   StartTLSPostgres,
+	"postgresql": StartTLSPostgres,
+	"imap":       StartTLSIMAP,
+	"pop3":       StartTLSPOP3,
+	"smtp":       StartTLSSMTP,
+	"submission": StartTLSSMTP,
+	"ldap":       StartTLSLDAP,
+	"xmpp":       StartTLSXMPP,
+}
+
+// targetStartTLS returns the protocol implied by target's URL scheme: the
+// STARTTLS protocol of a plaintext scheme, none for a scheme that speaks TLS
+// from the start, and auto when target is not a URL or the scheme is unknown.
+func targetStartTLS(target string) StartTLS {
+	if !strings.Contains(target, "://") {
+		return StartTLSAuto
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return StartTLSAuto
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if protocol, ok := schemeStartTLS[scheme]; ok {
+		return protocol
+	}
+	if _, ok := schemePorts[scheme]; ok {
+		return StartTLSNone
+	}
+	return StartTLSAuto
+}
+
+// GetAddress returns host as a "host:port" address suitable for dialing,
+// adding defaultPort when host has none. host may be a name, an IPv4 address,
+// a bare or bracketed IPv6 address, optionally with a zone, or any of those
+// followed by a port. It may also be a URL, in which case the scheme picks the
+// default port and any userinfo, path, query or fragment is ignored.
 func GetAddress(host string, defaultPort int) (string, error) {
 	if host == "" {
 		return "", ErrNoHostProvided
 	}
-	index := strings.LastIndex(host, ":")
-	if index < 0 {
-		return fmt.Sprintf("%s:%d", host, defaultPort), nil
+
+	if strings.Contains(host, "://") {
+		return getURLAddress(host)
 	}
 
-	strPort := host[index+1:]
-	_, err := strconv.Atoi(strPort)
-	if err != nil {
+	// A bare IPv6 address is full of colons but never has a port.
+	if isIPv6(host) {
+		return net.JoinHostPort(host, strconv.Itoa(defaultPort)), nil
+	}
+
+	// A bracketed IPv6 address without a port.
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		inner := host[1 : len(host)-1]
+		if !isIPv6(inner) {
+			return "", NewErrInvalidHost(host)
+		}
+		return net.JoinHostPort(inner, strconv.Itoa(defaultPort)), nil
+	}
+
+	if !strings.Contains(host, ":") {
+		return net.JoinHostPort(host, strconv.Itoa(defaultPort)), nil
+	}
+
+	hostname, strPort, err := net.SplitHostPort(host)
+	if err != nil || hostname == "" {
 		return "", NewErrInvalidHost(host)
 	}
 
-	return host, nil
+	port, err := strconv.Atoi(strPort)
+	if err != nil || port < 1 || port > 65535 {
+		return "", NewErrInvalidHost(host)
+	}
+
+	return net.JoinHostPort(hostname, strPort), nil
+}
+
+func getURLAddress(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return "", NewErrInvalidHost(rawURL)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	defaultPort, ok := schemePorts[scheme]
+	if !ok {
+		return "", NewErrUnsupportedScheme(scheme)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = strconv.Itoa(defaultPort)
+	}
+
+	address, err := GetAddress(net.JoinHostPort(u.Hostname(), port), defaultPort)
+	if err != nil {
+		return "", NewErrInvalidHost(rawURL)
+	}
+	return address, nil
+}
+
+// isIPv6 reports whether s is an IPv6 address, optionally with a zone such as
+// "fe80::1%eth0".
+func isIPv6(s string) bool {
+	addr, err := netip.ParseAddr(s)
+	return err == nil && addr.Is6()
 }
 
+// stripZone removes the zone from an IPv6 address, "fe80::1%eth0" becomes
+// "fe80::1". Anything else is returned unchanged.
+func stripZone(host string) string {
+	if !isIPv6(host) {
+		return host
+	}
+	addr, _ := netip.ParseAddr(host)
+	return addr.WithZone("").String()
+}
@@ -0,0 +1,212 @@
+package tls
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+)
+
+// TLS record and handshake message types, RFC 5246.
+const (
+	recordTypeAlert     = 0x15
+	recordTypeHandshake = 0x16
+	typeClientHello     = 0x01
+	typeServerHello     = 0x02
+)
+
+// TLS extension numbers used by writeClientHello.
+const (
+	extensionServerName          = 0
+	extensionSupportedGroups     = 10
+	extensionECPointFormats      = 11
+	extensionSignatureAlgorithms = 13
+	extensionExtendedMaster      = 23
+	extensionSupportedVersions   = 43
+	extensionKeyShare            = 51
+	extensionRenegotiationInfo   = 0xff01
+)
+
+// groupX25519 is the key exchange group of the key share offered for TLS 1.3.
+const groupX25519 = 29
+
+// helloGroups are x25519, secp256r1, secp384r1 and secp521r1.
+var helloGroups = []uint16{groupX25519, 23, 24, 25}
+
+// helloSignatureAlgorithms are the RSA PKCS#1, ECDSA, RSA-PSS and Ed25519
+// schemes, with SHA-1 last for old servers.
+var helloSignatureAlgorithms = []uint16{
+	0x0403, 0x0503, 0x0603, 0x0804, 0x0805, 0x0806, 0x0401, 0x0501, 0x0601, 0x0807, 0x0201, 0x0203,
+}
+
+// serverChoice sends a ClientHello for version offering suites in exactly the
+// order given and returns the suite the server picks. crypto/tls always offers
+// suites in its own order, and every TLS 1.3 suite whatever it is configured
+// with, which makes it useless for telling which TLS 1.3 suites a server
+// accepts or whether a server honours the client's preference.
+func serverChoice(conn net.Conn, version uint16, serverName string, suites []uint16) (uint16, error) {
+	if err := writeClientHello(conn, version, serverName, suites); err != nil {
+		return 0, err
+	}
+
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return 0, fmt.Errorf("failed to read ServerHello: %w", err)
+	}
+	body := make([]byte, binary.BigEndian.Uint16(header[3:5]))
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return 0, fmt.Errorf("failed to read ServerHello: %w", err)
+	}
+
+	switch {
+	case header[0] == recordTypeAlert && len(body) == 2:
+		return 0, fmt.Errorf("server sent alert %d", body[1])
+	case header[0] != recordTypeHandshake || len(body) < 4 || body[0] != typeServerHello:
+		return 0, fmt.Errorf("server did not reply with a ServerHello")
+	}
+
+	// Skip the message header, version and random to reach the session ID.
+	hello := body[4:]
+	if len(hello) < 2+32+1 {
+		return 0, fmt.Errorf("ServerHello is truncated")
+	}
+	sessionIDLength := int(hello[2+32])
+	offset := 2 + 32 + 1 + sessionIDLength
+	if len(hello) < offset+2 {
+		return 0, fmt.Errorf("ServerHello is truncated")
+	}
+	suite := binary.BigEndian.Uint16(hello[offset:])
+
+	// A TLS 1.3 server says so in supported_versions, as the ServerHello
+	// version field stays at TLS 1.2. A HelloRetryRequest looks the same
+	// and names the suite too.
+	if version == tls.VersionTLS13 {
+		if selected, ok := selectedVersion(hello[offset+2:]); !ok || selected != tls.VersionTLS13 {
+			return 0, fmt.Errorf("server did not negotiate TLS 1.3")
+		}
+	}
+	return suite, nil
+}
+
+// selectedVersion returns the version in the supported_versions extension of
+// the rest of a ServerHello after the cipher suite.
+func selectedVersion(rest []byte) (uint16, bool) {
+	// Skip the compression method to reach the extensions.
+	if len(rest) < 1+2 {
+		return 0, false
+	}
+	extensions := rest[1:]
+	length := int(binary.BigEndian.Uint16(extensions))
+	extensions = extensions[2:]
+	if len(extensions) < length {
+		return 0, false
+	}
+	extensions = extensions[:length]
+
+	for len(extensions) >= 4 {
+		extension := binary.BigEndian.Uint16(extensions)
+		dataLength := int(binary.BigEndian.Uint16(extensions[2:]))
+		extensions = extensions[4:]
+		if len(extensions) < dataLength {
+			return 0, false
+		}
+		if extension == extensionSupportedVersions && dataLength == 2 {
+			return binary.BigEndian.Uint16(extensions), true
+		}
+		extensions = extensions[dataLength:]
+	}
+	return 0, false
+}
+
+func writeClientHello(w io.Writer, version uint16, serverName string, suites []uint16) error {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return err
+	}
+
+	// TLS 1.3 is offered in supported_versions, with the version field left
+	// at TLS 1.2 for servers that do not know it.
+	legacyVersion := min(version, tls.VersionTLS12)
+
+	var hello []byte
+	hello = binary.BigEndian.AppendUint16(hello, legacyVersion)
+	hello = append(hello, random...)
+	hello = append(hello, 0) // no session ID
+	hello = binary.BigEndian.AppendUint16(hello, uint16(2*len(suites)))
+	for _, suite := range suites {
+		hello = binary.BigEndian.AppendUint16(hello, suite)
+	}
+	hello = append(hello, 1, 0) // null compression only
+
+	var extensions []byte
+	if serverName != "" {
+		var name []byte
+		name = binary.BigEndian.AppendUint16(name, uint16(len(serverName)+3))
+		name = append(name, 0) // host_name
+		name = binary.BigEndian.AppendUint16(name, uint16(len(serverName)))
+		name = append(name, serverName...)
+		extensions = appendExtension(extensions, extensionServerName, name)
+	}
+	extensions = appendExtension(extensions, extensionSupportedGroups, uint16List(helloGroups))
+	extensions = appendExtension(extensions, extensionECPointFormats, []byte{1, 0})
+	extensions = appendExtension(extensions, extensionSignatureAlgorithms, uint16List(helloSignatureAlgorithms))
+	extensions = appendExtension(extensions, extensionExtendedMaster, nil)
+	extensions = appendExtension(extensions, extensionRenegotiationInfo, []byte{0})
+	if version == tls.VersionTLS13 {
+		keyShare, err := x25519KeyShare()
+		if err != nil {
+			return err
+		}
+		extensions = appendExtension(extensions, extensionSupportedVersions, []byte{2, 0x03, 0x04})
+		extensions = appendExtension(extensions, extensionKeyShare, keyShare)
+	}
+	hello = binary.BigEndian.AppendUint16(hello, uint16(len(extensions)))
+	hello = append(hello, extensions...)
+
+	message := []byte{typeClientHello, byte(len(hello) >> 16), byte(len(hello) >> 8), byte(len(hello))}
+	message = append(message, hello...)
+
+	// The record version is TLS 1.0 whatever is offered, for old servers.
+	record := []byte{recordTypeHandshake, 0x03, 0x01}
+	record = binary.BigEndian.AppendUint16(record, uint16(len(message)))
+	record = append(record, message...)
+
+	_, err := w.Write(record)
+	return err
+}
+
+// x25519KeyShare returns a key_share extension holding the public half of a
+// throwaway x25519 key. The handshake never gets far enough to need the rest.
+func x25519KeyShare() ([]byte, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	public := key.PublicKey().Bytes()
+
+	var entry []byte
+	entry = binary.BigEndian.AppendUint16(entry, groupX25519)
+	entry = binary.BigEndian.AppendUint16(entry, uint16(len(public)))
+	entry = append(entry, public...)
+
+	share := binary.BigEndian.AppendUint16(nil, uint16(len(entry)))
+	return append(share, entry...), nil
+}
+
+func appendExtension(b []byte, extension uint16, data []byte) []byte {
+	b = binary.BigEndian.AppendUint16(b, extension)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(data)))
+	return append(b, data...)
+}
+
+// uint16List encodes values as a list with a two byte length prefix.
+func uint16List(values []uint16) []byte {
+	b := binary.BigEndian.AppendUint16(nil, uint16(2*len(values)))
+	for _, v := range values {
+		b = binary.BigEndian.AppendUint16(b, v)
+	}
+	return b
+}
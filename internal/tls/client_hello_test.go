@@ -0,0 +1,82 @@
+package tls
+
+import (
+	"crypto/tls"
+	"net"
+	"testing"
+
+	"github.com/kevholditch/tls/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveHello completes a handshake as a server of up to maxVersion offering
+// suites over the server end of a pipe and returns the client end. crypto/tls
+// always offers every TLS 1.3 suite.
+func serveHello(t *testing.T, maxVersion uint16, suites ...uint16) net.Conn {
+	t.Helper()
+
+	cert := testutil.NewCertBuilder().WithDefault().Build()
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = server.Close()
+	})
+
+	go func() {
+		_ = tls.Server(server, &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MaxVersion:   maxVersion,
+			CipherSuites: suites,
+		}).Handshake()
+	}()
+
+	return client
+}
+
+func TestServerChoice(t *testing.T) {
+	conn := serveHello(t, tls.VersionTLS12, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA)
+
+	chosen, err := serverChoice(conn, tls.VersionTLS12, "example.com",
+		[]uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256})
+
+	assert.NoError(t, err)
+	assert.Equal(t, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, chosen)
+}
+
+func TestServerChoice_NoSharedSuiteShouldReturnAlert(t *testing.T) {
+	conn := serveHello(t, tls.VersionTLS12, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256)
+
+	_, err := serverChoice(conn, tls.VersionTLS12, "example.com", []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA})
+
+	assert.EqualError(t, err, "server sent alert 40")
+}
+
+func TestServerChoice_TLS13(t *testing.T) {
+	conn := serveHello(t, tls.VersionTLS13)
+
+	chosen, err := serverChoice(conn, tls.VersionTLS13, "example.com", []uint16{tls.TLS_CHACHA20_POLY1305_SHA256})
+
+	assert.NoError(t, err)
+	assert.Equal(t, tls.TLS_CHACHA20_POLY1305_SHA256, chosen)
+}
+
+func TestServerChoice_TLS13NotSupportedShouldReturnAlert(t *testing.T) {
+	conn := serveHello(t, tls.VersionTLS12, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256)
+
+	_, err := serverChoice(conn, tls.VersionTLS13, "example.com", []uint16{tls.TLS_AES_128_GCM_SHA256})
+
+	assert.EqualError(t, err, "server sent alert 70")
+}
+
+func TestSelectedVersion(t *testing.T) {
+	// Compression method, then extensions: key_share then supported_versions.
+	rest := []byte{0x00, 0x00, 0x0a, 0x00, 0x33, 0x00, 0x00, 0x00, 0x2b, 0x00, 0x02, 0x03, 0x04}
+
+	version, ok := selectedVersion(rest)
+
+	assert.True(t, ok)
+	assert.Equal(t, uint16(tls.VersionTLS13), version)
+
+	_, ok = selectedVersion(rest[:9])
+	assert.False(t, ok)
+}
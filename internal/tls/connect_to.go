@@ -0,0 +1,97 @@
+package tls
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ConnectTo redirects connections for one host and port to another address
+// without changing the server name presented in the handshake. Empty fields
+// match any host or port, or leave them unchanged.
+type ConnectTo struct {
+	FromHost string
+	FromPort string
+	ToHost   string
+	ToPort   string
+}
+
+// ParseConnectTo parses a curl style --connect-to value,
+// "HOST1:PORT1:HOST2:PORT2". IPv6 addresses must be in brackets.
+func ParseConnectTo(s string) (ConnectTo, error) {
+	parts := splitOutsideBrackets(s)
+	if len(parts) != 4 {
+		return ConnectTo{}, fmt.Errorf("invalid connect-to: %s (must be HOST1:PORT1:HOST2:PORT2)", s)
+	}
+
+	return ConnectTo{
+		FromHost: trimBrackets(parts[0]),
+		FromPort: parts[1],
+		ToHost:   trimBrackets(parts[2]),
+		ToPort:   parts[3],
+	}, nil
+}
+
+// ParseResolve parses a curl style --resolve value, "HOST:PORT:ADDRESS",
+// which pins HOST:PORT to ADDRESS on the same port.
+func ParseResolve(s string) (ConnectTo, error) {
+	parts := splitOutsideBrackets(s)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return ConnectTo{}, fmt.Errorf("invalid resolve: %s (must be HOST:PORT:ADDRESS)", s)
+	}
+
+	return ConnectTo{
+		FromHost: trimBrackets(parts[0]),
+		FromPort: parts[1],
+		ToHost:   trimBrackets(parts[2]),
+	}, nil
+}
+
+func (c ConnectTo) matches(host, port string) bool {
+	return (c.FromHost == "" || strings.EqualFold(c.FromHost, host)) &&
+		(c.FromPort == "" || c.FromPort == port)
+}
+
+// dialAddress returns the address to dial for host and port, applying the
+// first rule in rules that matches.
+func dialAddress(rules []ConnectTo, host, port string) string {
+	for _, rule := range rules {
+		if !rule.matches(host, port) {
+			continue
+		}
+		if rule.ToHost != "" {
+			host = rule.ToHost
+		}
+		if rule.ToPort != "" {
+			port = rule.ToPort
+		}
+		break
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// splitOutsideBrackets splits s on every colon that is not inside square
+// brackets, so "[::1]:443" splits into "[::1]" and "443".
+func splitOutsideBrackets(s string) []string {
+	var parts []string
+	depth := 0
+	start := 0
+	for i, r := range s {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ':':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func trimBrackets(s string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+}
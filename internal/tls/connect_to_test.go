@@ -0,0 +1,53 @@
+package tls
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConnectTo(t *testing.T) {
+	rule, err := ParseConnectTo("example.com:443:10.0.0.5:8443")
+
+	assert.NoError(t, err)
+	assert.Equal(t, ConnectTo{FromHost: "example.com", FromPort: "443", ToHost: "10.0.0.5", ToPort: "8443"}, rule)
+}
+
+func TestParseConnectTo_EmptyFieldsAndIPv6(t *testing.T) {
+	rule, err := ParseConnectTo("::[2001:db8::1]:")
+
+	assert.NoError(t, err)
+	assert.Equal(t, ConnectTo{ToHost: "2001:db8::1"}, rule)
+}
+
+func TestParseConnectTo_WrongNumberOfFieldsShouldReturnError(t *testing.T) {
+	_, err := ParseConnectTo("example.com:443:10.0.0.5")
+
+	assert.EqualError(t, err, "invalid connect-to: example.com:443:10.0.0.5 (must be HOST1:PORT1:HOST2:PORT2)")
+}
+
+func TestParseResolve(t *testing.T) {
+	rule, err := ParseResolve("example.com:443:[::1]")
+
+	assert.NoError(t, err)
+	assert.Equal(t, ConnectTo{FromHost: "example.com", FromPort: "443", ToHost: "::1"}, rule)
+}
+
+func TestParseResolve_MissingAddressShouldReturnError(t *testing.T) {
+	_, err := ParseResolve("example.com:443:")
+
+	assert.EqualError(t, err, "invalid resolve: example.com:443: (must be HOST:PORT:ADDRESS)")
+}
+
+func TestDialAddress(t *testing.T) {
+	rules := []ConnectTo{
+		{FromHost: "example.com", FromPort: "443", ToHost: "10.0.0.5"},
+		{FromHost: "example.com", ToHost: "10.0.0.6", ToPort: "8443"},
+		{FromPort: "993", ToHost: "::1"},
+	}
+
+	assert.Equal(t, "10.0.0.5:443", dialAddress(rules, "example.com", "443"))
+	assert.Equal(t, "10.0.0.6:8443", dialAddress(rules, "EXAMPLE.com", "444"))
+	assert.Equal(t, "[::1]:993", dialAddress(rules, "mail.example.com", "993"))
+	assert.Equal(t, "other.com:443", dialAddress(rules, "other.com", "443"))
+}
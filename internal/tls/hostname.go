@@ -0,0 +1,74 @@
+package tls
+
+import (
+	"crypto/x509"
+	"net"
+	"strings"
+)
+
+// HostnameMatch describes whether a certificate covers a host.
+type HostnameMatch struct {
+	Host string
+	// Err is the error from x509.Certificate.VerifyHostname, nil when the
+	// certificate is valid for Host.
+	Err error
+	// Matched is the SAN entry that covers Host, empty when none do.
+	Matched string
+	// Wildcard is true when Matched is a wildcard DNS name.
+	Wildcard bool
+	// CommonNameOnly is true when the certificate has no SANs and Host only
+	// appears in the legacy Common Name, which modern clients ignore.
+	CommonNameOnly bool
+}
+
+// MatchHostname checks whether cert is valid for host and, if it is, which
+// SAN entry matched.
+func MatchHostname(cert *x509.Certificate, host string) HostnameMatch {
+	m := HostnameMatch{
+		Host: host,
+		Err:  cert.VerifyHostname(host),
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		for _, candidate := range cert.IPAddresses {
+			if candidate.Equal(ip) {
+				m.Matched = candidate.String()
+				return m
+			}
+		}
+		return m
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, dnsName := range cert.DNSNames {
+		pattern := strings.ToLower(strings.TrimSuffix(dnsName, "."))
+		if pattern == name {
+			m.Matched = dnsName
+			return m
+		}
+		if matchesWildcard(pattern, name) {
+			m.Matched = dnsName
+			m.Wildcard = true
+			return m
+		}
+	}
+
+	if len(cert.DNSNames) == 0 && len(cert.IPAddresses) == 0 &&
+		strings.EqualFold(strings.TrimSuffix(cert.Subject.CommonName, "."), name) {
+		m.CommonNameOnly = true
+	}
+
+	return m
+}
+
+// matchesWildcard reports whether pattern, such as "*.example.com", covers
+// name. A wildcard only ever stands in for the single left-most label.
+func matchesWildcard(pattern, name string) bool {
+	suffix, ok := strings.CutPrefix(pattern, "*.")
+	if !ok {
+		return false
+	}
+
+	label, rest, ok := strings.Cut(name, ".")
+	return ok && label != "" && rest == suffix
+}
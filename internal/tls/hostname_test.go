@@ -0,0 +1,66 @@
+package tls
+
+import (
+	"net"
+	"testing"
+
+	"github.com/kevholditch/tls/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchHostname_ExactDNSName(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithDNSNames("example.com", "api.example.com").BuildCert()
+
+	m := MatchHostname(cert, "API.example.com")
+
+	assert.NoError(t, m.Err)
+	assert.Equal(t, "api.example.com", m.Matched)
+	assert.False(t, m.Wildcard)
+}
+
+func TestMatchHostname_Wildcard(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithDNSNames("*.example.com").BuildCert()
+
+	m := MatchHostname(cert, "api.example.com")
+
+	assert.NoError(t, m.Err)
+	assert.Equal(t, "*.example.com", m.Matched)
+	assert.True(t, m.Wildcard)
+}
+
+func TestMatchHostname_WildcardDoesNotMatchMultipleLabels(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithDNSNames("*.example.com").BuildCert()
+
+	m := MatchHostname(cert, "a.b.example.com")
+
+	assert.Error(t, m.Err)
+	assert.Equal(t, "", m.Matched)
+}
+
+func TestMatchHostname_WildcardDoesNotMatchApex(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithDNSNames("*.example.com").BuildCert()
+
+	m := MatchHostname(cert, "example.com")
+
+	assert.Error(t, m.Err)
+	assert.Equal(t, "", m.Matched)
+}
+
+func TestMatchHostname_IPAddress(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithIPAddresses(net.ParseIP("10.0.0.1")).BuildCert()
+
+	m := MatchHostname(cert, "10.0.0.1")
+
+	assert.NoError(t, m.Err)
+	assert.Equal(t, "10.0.0.1", m.Matched)
+}
+
+func TestMatchHostname_CommonNameOnly(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithCommonName("legacy.example.com").BuildCert()
+
+	m := MatchHostname(cert, "legacy.example.com")
+
+	assert.Error(t, m.Err)
+	assert.Equal(t, "", m.Matched)
+	assert.True(t, m.CommonNameOnly)
+}
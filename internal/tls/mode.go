@@ -7,6 +7,9 @@ import (
 
 const defaultPort = 443
 
+// Stdin is the target that reads certificates from standard input.
+const Stdin = "-"
+
 type Mode string
 
 const (
@@ -15,6 +18,9 @@ const (
 	ModeServer Mode = "server"
 )
 
+// fileExtensions are the suffixes that mark a target as a certificate file.
+var fileExtensions = []string{".pem", ".der", ".cer", ".crt"}
+
 func ParseMode(s string) (Mode, error) {
 	s = strings.ToLower(strings.TrimSpace(s))
 	mode := Mode(s)
@@ -29,7 +35,11 @@ func ParseMode(s string) (Mode, error) {
 func DetectMode(arg string) Mode {
 	a := strings.ToLower(arg)
 
-	if strings.HasPrefix(a, "https://") || strings.HasPrefix(a, "http://") {
+	if a == Stdin {
+		return ModeFile
+	}
+
+	if strings.Contains(a, "://") {
 		return ModeServer
 	}
 
@@ -37,8 +47,10 @@ func DetectMode(arg string) Mode {
 		return ModeFile
 	}
 
-	if strings.HasSuffix(a, ".pem") {
-		return ModeFile
+	for _, ext := range fileExtensions {
+		if strings.HasSuffix(a, ext) {
+			return ModeFile
+		}
 	}
 
 	if strings.Contains(a, ":") {
@@ -47,4 +59,3 @@ func DetectMode(arg string) Mode {
 
 	return ModeServer
 }
-
@@ -11,12 +11,22 @@ func TestDetectsFileMode(t *testing.T) {
 	assert.Equal(t, ModeFile, DetectMode("./foo.crt"))
 	assert.Equal(t, ModeFile, DetectMode("./foo"))
 	assert.Equal(t, ModeFile, DetectMode("/foo/bar"))
+	assert.Equal(t, ModeFile, DetectMode("-"))
+	assert.Equal(t, ModeFile, DetectMode("cert.der"))
+	assert.Equal(t, ModeFile, DetectMode("cert.cer"))
+	assert.Equal(t, ModeFile, DetectMode("cert.crt"))
 }
 
 func TestDetectsServerMode(t *testing.T) {
 	assert.Equal(t, ModeServer, DetectMode("example.com:8443"))
 	assert.Equal(t, ModeServer, DetectMode("https://bar"))
 	assert.Equal(t, ModeServer, DetectMode("https://bar:443"))
+	assert.Equal(t, ModeServer, DetectMode("https://example.com/path/cert.pem"))
+	assert.Equal(t, ModeServer, DetectMode("ldaps://ldap.example.com"))
+	assert.Equal(t, ModeServer, DetectMode("::1"))
+	assert.Equal(t, ModeServer, DetectMode("2001:db8::1"))
+	assert.Equal(t, ModeServer, DetectMode("[::1]:8443"))
+	assert.Equal(t, ModeServer, DetectMode("fe80::1%eth0"))
 }
 
 func TestParseModeValidModes(t *testing.T) {
@@ -69,4 +79,3 @@ func TestParseModeInvalidModes(t *testing.T) {
 		})
 	}
 }
-
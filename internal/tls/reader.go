@@ -1,19 +1,86 @@
 package tls
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"fmt"
+	"io"
+	"net"
 	"os"
+	"time"
 )
 
-func Read(host string, mode Mode) (*x509.Certificate, error) {
+// asn1Sequence is the first byte of every DER encoded certificate.
+const asn1Sequence = 0x30
+
+// Result holds the certificates read from a target.
+type Result struct {
+	Certificates []*x509.Certificate
+	// ServerName is the name of the server the certificates were read from,
+	// the SNI when one was set and otherwise the host. It is empty when they
+	// were read from a file.
+	ServerName string
+	// Skipped lists the type of every PEM block that was ignored because it
+	// did not hold a certificate, e.g. "PRIVATE KEY".
+	Skipped []string
+	// Address is the address that was dialed, after any ConnectTo redirect.
+	// It is empty when the certificates were read from a file.
+	Address string
+	// SNI is the server name sent in the handshake, empty when none was.
+	SNI string
+	// Connection is the state of the negotiated TLS connection, nil when the
+	// certificates were read from a file.
+	Connection *tls.ConnectionState
+}
+
+// ServerOptions controls how ReadServer connects to a server.
+type ServerOptions struct {
+	// SNI is the server name sent in the handshake, the host being read
+	// from when empty.
+	SNI string
+	// NoSNI sends no server name at all, to see a server's default
+	// certificate.
+	NoSNI bool
+	// ConnectTo redirects the connection to a different address, e.g. a
+	// single load balancer node, while presenting the original server name.
+	ConnectTo []ConnectTo
+	// StartTLS is the plaintext protocol to upgrade before the handshake.
+	// The zero value and StartTLSAuto pick one from well-known ports.
+	StartTLS StartTLS
+	// Generic is the exchange to perform when StartTLS is StartTLSGeneric.
+	Generic GenericStartTLS
+	// Proxy picks the proxy to tunnel through for a host. A nil Proxy, or
+	// one that returns a nil URL, connects directly.
+	Proxy ProxyFunc
+	// Timeout limits the whole read, from looking up the host to the end of
+	// the handshake. Zero means no limit beyond the context's.
+	Timeout time.Duration
+	// HandshakeTimeout limits any STARTTLS exchange and the TLS handshake
+	// once connected. Zero means no limit beyond Timeout.
+	HandshakeTimeout time.Duration
+	// ALPN lists the application protocols to offer, e.g. "h2". None are
+	// offered when it is empty, as a server that only speaks some other
+	// protocol fails the handshake when offered protocols it does not know.
+	ALPN []string
+}
+
+// Read reads certificates from host, which is a server, a file path or Stdin
+// depending on mode. stdin is only read from when host is Stdin, and ctx and
+// opts are only used for servers.
+func Read(ctx context.Context, host string, mode Mode, stdin io.Reader, opts ServerOptions) (*Result, error) {
 
 	if mode == ModeAuto {
 		mode = DetectMode(host)
 	}
 
+	if mode == ModeFile && host == Stdin {
+		return ReadReader(stdin)
+	}
+
 	if mode == ModeFile {
 		return ReadFile(host)
 	}
@@ -24,43 +91,245 @@ func Read(host string, mode Mode) (*x509.Certificate, error) {
 
 	}
 
-	return ReadServer(addr)
+	return ReadServer(ctx, addr, opts.forTarget(host))
+}
+
+// forTarget returns opts with StartTLS picked by target's URL scheme when it
+// is left to auto, so smtp://mail.example.com:2525 still upgrades with SMTP.
+// GetAddress drops the scheme, so this has to happen before it.
+func (opts ServerOptions) forTarget(target string) ServerOptions {
+	if opts.StartTLS == "" || opts.StartTLS == StartTLSAuto {
+		opts.StartTLS = targetStartTLS(target)
+	}
+	return opts
+}
+
+// ReadServer returns every certificate the server presented, in the order it
+// sent them: the leaf first, followed by any intermediates and roots. It gives
+// up with context.Canceled when ctx is cancelled, and with an ErrTimeout naming
+// the phase that was too slow when a deadline passes.
+func ReadServer(ctx context.Context, host string, opts ServerOptions) (*Result, error) {
+	result, err := handshake(ctx, host, opts, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Certificates) == 0 {
+		return nil, fmt.Errorf("no certificates found for %s", host)
+	}
+
+	return result, nil
+}
+
+// handshakeError is a failure of the TLS handshake itself, once connected and
+// upgraded, as opposed to a failure to reach the server. Scans expect these,
+// because servers refuse the versions and cipher suites they do not support.
+type handshakeError struct {
+	err error
+}
+
+func (e *handshakeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *handshakeError) Unwrap() error {
+	return e.err
 }
 
-func ReadServer(host string) (*x509.Certificate, error) {
-	conn, err := tls.Dial("tcp", host, &tls.Config{InsecureSkipVerify: true})
+// handshake connects to host and completes a TLS handshake, after passing the
+// config to configure, if set, to constrain what is offered.
+func handshake(ctx context.Context, host string, opts ServerOptions, configure func(*tls.Config)) (*Result, error) {
+	return connect(ctx, host, opts, configure, func(ctx context.Context, conn net.Conn, config *tls.Config, result *Result) error {
+		// tls.Client, unlike tls.Dial, leaves an empty ServerName alone
+		// rather than filling it in from the address, which NoSNI relies on.
+		client := tls.Client(conn, config)
+		if err := client.HandshakeContext(ctx); err != nil {
+			return err
+		}
+
+		state := client.ConnectionState()
+		result.Certificates = state.PeerCertificates
+		result.Connection = &state
+		return nil
+	})
+}
+
+// exchangeFunc speaks TLS over conn, a connection to the server that is
+// ready for a ClientHello, using config and filling in result.
+type exchangeFunc func(ctx context.Context, conn net.Conn, config *tls.Config, result *Result) error
+
+// connect dials host, upgrades the connection with STARTTLS if needed and
+// runs exchange over it. Errors from exchange are handshakeErrors.
+func connect(ctx context.Context, host string, opts ServerOptions, configure func(*tls.Config), exchange exchangeFunc) (*Result, error) {
+	hostName, port, err := net.SplitHostPort(host)
 	if err != nil {
 		return nil, err
 	}
-	defer func(conn *tls.Conn) {
+
+	// Certificates never name a zone, so it is only used for dialing.
+	serverName := stripZone(hostName)
+	if opts.SNI != "" {
+		serverName = opts.SNI
+	}
+
+	config := &tls.Config{InsecureSkipVerify: true, ServerName: serverName}
+	if opts.NoSNI {
+		config.ServerName = ""
+	}
+
+	protocol := opts.StartTLS.resolve(port)
+	config.NextProtos = opts.ALPN
+
+	if configure != nil {
+		configure(config)
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	addr := dialAddress(opts.ConnectTo, hostName, port)
+	rawConn, err := dial(ctx, opts, hostName, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer func(conn net.Conn) {
 		_ = conn.Close()
-	}(conn)
+	}(rawConn)
 
-	state := conn.ConnectionState()
+	if opts.HandshakeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.HandshakeTimeout)
+		defer cancel()
+	}
+	defer unblockOnDone(ctx, rawConn)()
 
-	if len(state.PeerCertificates) == 0 {
-		return nil, fmt.Errorf("no certificates found for %s", host)
+	upgraded, err := negotiateStartTLS(rawConn, protocol, serverName, opts.Generic)
+	if err != nil {
+		return nil, phaseError(ctx, PhaseHandshake, host, err)
+	}
+
+	result := &Result{ServerName: serverName, Address: addr, SNI: config.ServerName}
+	if err := exchange(ctx, upgraded, config, result); err != nil {
+		return nil, &handshakeError{err: phaseError(ctx, PhaseHandshake, host, err)}
+	}
+	return result, nil
+}
+
+// dial connects to addr, either directly or through the proxy opts picks for
+// host.
+func dial(ctx context.Context, opts ServerOptions, host, addr string) (net.Conn, error) {
+	if opts.Proxy == nil {
+		return dialTCP(ctx, addr)
 	}
 
-	return state.PeerCertificates[0], nil
+	proxy, err := opts.Proxy(stripZone(host))
+	if err != nil {
+		return nil, err
+	}
+	if proxy == nil {
+		return dialTCP(ctx, addr)
+	}
+	return dialProxy(ctx, proxy, addr)
 }
 
-func ReadFile(path string) (*x509.Certificate, error) {
+func ReadFile(path string) (*Result, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	block, _ := pem.Decode(data)
-	if block == nil {
-		return nil, fmt.Errorf("failed to decode PEM block")
+	return Parse(data)
+}
+
+// ReadReader reads r to the end and parses the certificates in it, exactly as
+// ReadFile does for a file.
+func ReadReader(r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
 	}
 
-	cert, err := x509.ParseCertificate(block.Bytes)
+	return Parse(data)
+}
+
+// Parse returns every certificate in data, which may be PEM, binary DER or
+// base64 encoded DER without PEM armor.
+func Parse(data []byte) (*Result, error) {
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		return ParsePEM(data)
+	}
+
+	// Data starting with a SEQUENCE could still be base64, which is tried
+	// before giving up, but the DER error explains a truncated or corrupt
+	// DER file far better than "not PEM, DER or base64".
+	var derErr error
+	if len(data) > 0 && data[0] == asn1Sequence {
+		result, err := ParseDER(data)
+		if err == nil {
+			return result, nil
+		}
+		derErr = err
+	}
+
+	if der, err := base64.StdEncoding.DecodeString(string(bytes.Join(bytes.Fields(data), nil))); err == nil {
+		return ParseDER(der)
+	}
+
+	if derErr != nil {
+		return nil, fmt.Errorf("failed to decode certificate: %w", derErr)
+	}
+	return nil, fmt.Errorf("failed to decode certificate: data is not PEM, DER or base64")
+}
+
+// ParseDER returns the certificates in data, which holds one or more DER
+// encoded certificates back to back.
+func ParseDER(data []byte) (*Result, error) {
+	certs, err := x509.ParseCertificates(data)
 	if err != nil {
 		return nil, err
 	}
 
-	return cert, nil
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found")
+	}
+
+	return &Result{Certificates: certs}, nil
 }
 
+// ParsePEM returns every certificate in data, which may hold any number of PEM
+// blocks. Blocks that are not certificates are skipped and recorded in the
+// result rather than treated as an error.
+func ParsePEM(data []byte) (*Result, error) {
+	result := &Result{}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			result.Skipped = append(result.Skipped, block.Type)
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate %d: %w", len(result.Certificates)+1, err)
+		}
+		result.Certificates = append(result.Certificates, cert)
+	}
+
+	if len(result.Certificates) == 0 {
+		if len(result.Skipped) == 0 {
+			return nil, fmt.Errorf("failed to decode PEM block")
+		}
+		return nil, fmt.Errorf("no certificates found, only %v", result.Skipped)
+	}
+
+	return result, nil
+}
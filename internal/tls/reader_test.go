@@ -0,0 +1,116 @@
+package tls
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/kevholditch/tls/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildDER(commonName string) []byte {
+	cert := testutil.NewCertBuilder().WithDefault().
+		WithSubject(func() pkix.Name { return pkix.Name{CommonName: commonName} }).
+		Build()
+	return cert.Certificate[0]
+}
+
+func buildPEM(commonName string) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: buildDER(commonName)})
+}
+
+func commonNames(certs []*x509.Certificate) []string {
+	var names []string
+	for _, cert := range certs {
+		names = append(names, cert.Subject.CommonName)
+	}
+	return names
+}
+
+func TestParsePEM_SingleCertificate(t *testing.T) {
+	result, err := ParsePEM(buildPEM("one"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"one"}, commonNames(result.Certificates))
+	assert.Empty(t, result.Skipped)
+}
+
+func TestParsePEM_ReadsEveryCertificateAndSkipsOtherBlocks(t *testing.T) {
+	var data []byte
+	data = append(data, buildPEM("one")...)
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{1, 2, 3}})...)
+	data = append(data, buildPEM("two")...)
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "EC PARAMETERS", Bytes: []byte{4}})...)
+	data = append(data, buildPEM("three")...)
+
+	result, err := ParsePEM(data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"one", "two", "three"}, commonNames(result.Certificates))
+	assert.Equal(t, []string{"PRIVATE KEY", "EC PARAMETERS"}, result.Skipped)
+}
+
+func TestParsePEM_NoPEMDataShouldReturnError(t *testing.T) {
+	result, err := ParsePEM([]byte("not a certificate"))
+
+	assert.EqualError(t, err, "failed to decode PEM block")
+	assert.Nil(t, result)
+}
+
+func TestParsePEM_OnlyNonCertificateBlocksShouldReturnError(t *testing.T) {
+	result, err := ParsePEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{1}}))
+
+	assert.EqualError(t, err, "no certificates found, only [PRIVATE KEY]")
+	assert.Nil(t, result)
+}
+
+func TestParse_PEM(t *testing.T) {
+	result, err := Parse(append(buildPEM("one"), buildPEM("two")...))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"one", "two"}, commonNames(result.Certificates))
+}
+
+func TestParse_DER(t *testing.T) {
+	result, err := Parse(buildDER("one"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"one"}, commonNames(result.Certificates))
+}
+
+func TestParse_ConcatenatedDER(t *testing.T) {
+	result, err := Parse(append(buildDER("one"), buildDER("two")...))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"one", "two"}, commonNames(result.Certificates))
+}
+
+func TestParse_Base64WithoutArmor(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(buildDER("one"))
+	wrapped := encoded[:64] + "\n" + encoded[64:] + "\n"
+
+	result, err := Parse([]byte(wrapped))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"one"}, commonNames(result.Certificates))
+}
+
+func TestParse_GarbageShouldReturnError(t *testing.T) {
+	result, err := Parse([]byte("not a certificate"))
+
+	assert.EqualError(t, err, "failed to decode certificate: data is not PEM, DER or base64")
+	assert.Nil(t, result)
+}
+
+func TestParse_TruncatedDERShouldReturnDERError(t *testing.T) {
+	der := buildDER("one")
+
+	result, err := Parse(der[:len(der)/2])
+
+	assert.ErrorContains(t, err, "failed to decode certificate: x509: ")
+	assert.NotContains(t, err.Error(), "not PEM, DER or base64")
+	assert.Nil(t, result)
+}
@@ -0,0 +1,301 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+)
+
+// scanVersions are the protocol versions ScanVersions tries, oldest first.
+// SSL 3.0 is not among them because crypto/tls cannot speak it.
+var scanVersions = []uint16{tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13}
+
+// VersionResult is whether a server accepted a handshake limited to a single
+// protocol version.
+type VersionResult struct {
+	Version uint16
+	// Name is the name of Version, e.g. "TLS 1.2".
+	Name     string
+	Accepted bool
+	// Deprecated is true for TLS 1.0 and 1.1, which RFC 8996 forbids.
+	Deprecated bool
+	// Err explains why the server did not accept the version.
+	Err error
+}
+
+// ScanVersions attempts a handshake limited to each of TLS 1.0 to 1.3 with
+// target, interpreted as for Read in server mode. A server refusing a version
+// is part of the result; only failing to reach the server is an error.
+func ScanVersions(ctx context.Context, target string, opts ServerOptions) ([]VersionResult, error) {
+	addr, err := GetAddress(target, defaultPort)
+	if err != nil {
+		return nil, err
+	}
+	opts = opts.forTarget(target)
+
+	// Offer every suite, so a server that only has legacy suites for a
+	// version is not mistaken for one that does not support it.
+	suites := allCipherSuites()
+
+	results := make([]VersionResult, 0, len(scanVersions))
+	for _, version := range scanVersions {
+		_, err := handshake(ctx, addr, opts, limitTo(version, suites))
+
+		rejection, err := probeOutcome(err)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, VersionResult{
+			Version:    version,
+			Name:       tls.VersionName(version),
+			Accepted:   rejection == nil,
+			Deprecated: version < tls.VersionTLS12,
+			Err:        rejection,
+		})
+	}
+	return results, nil
+}
+
+// probeOutcome sorts the error from a handshake that offered only part of
+// what a client could: a failed handshake is a rejection, because servers
+// refuse what they do not support, while anything else ends the scan.
+func probeOutcome(err error) (rejection error, fatal error) {
+	if err == nil {
+		return nil, nil
+	}
+
+	var hsErr *handshakeError
+	if errors.As(err, &hsErr) && !errors.Is(err, context.Canceled) {
+		return err, nil
+	}
+	return nil, err
+}
+
+// allCipherSuites returns the IDs of every cipher suite crypto/tls
+// implements, including the insecure ones it does not offer by default.
+func allCipherSuites() []uint16 {
+	var ids []uint16
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		ids = append(ids, suite.ID)
+	}
+	return ids
+}
+
+// limitTo returns a configure function for handshake that offers only
+// version and suites.
+func limitTo(version uint16, suites []uint16) func(*tls.Config) {
+	return func(config *tls.Config) {
+		config.MinVersion = version
+		config.MaxVersion = version
+		config.CipherSuites = suites
+	}
+}
+
+// cipherSuitesFor returns every cipher suite crypto/tls implements for
+// version.
+func cipherSuitesFor(version uint16) []uint16 {
+	var ids []uint16
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		if slices.Contains(suite.SupportedVersions, version) {
+			ids = append(ids, suite.ID)
+		}
+	}
+	return ids
+}
+
+// CipherGrade rates a cipher suite.
+type CipherGrade string
+
+const (
+	GradeStrong   CipherGrade = "strong"
+	GradeWeak     CipherGrade = "weak"
+	GradeInsecure CipherGrade = "insecure"
+)
+
+// CipherResult is a cipher suite a server accepted.
+type CipherResult struct {
+	ID   uint16
+	Name string
+	// Grade is insecure for the suites crypto/tls considers insecure, weak
+	// for the rest with any Weaknesses and strong otherwise.
+	Grade CipherGrade
+	// Weaknesses lists what is wrong with the suite: "CBC", "3DES", "RC4"
+	// or "no PFS" for RSA key exchange.
+	Weaknesses []string
+}
+
+// NewCipherResult grades the cipher suite id.
+func NewCipherResult(id uint16) CipherResult {
+	name := tls.CipherSuiteName(id)
+	c := CipherResult{ID: id, Name: name, Grade: GradeStrong}
+
+	if strings.Contains(name, "_CBC_") {
+		c.Weaknesses = append(c.Weaknesses, "CBC")
+	}
+	if strings.Contains(name, "3DES") {
+		c.Weaknesses = append(c.Weaknesses, "3DES")
+	}
+	if strings.Contains(name, "RC4") {
+		c.Weaknesses = append(c.Weaknesses, "RC4")
+	}
+	if strings.HasPrefix(name, "TLS_RSA_") {
+		c.Weaknesses = append(c.Weaknesses, "no PFS")
+	}
+
+	if len(c.Weaknesses) > 0 {
+		c.Grade = GradeWeak
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		if suite.ID == id {
+			c.Grade = GradeInsecure
+		}
+	}
+	return c
+}
+
+// VersionCiphers are the cipher suites a server accepted for one protocol
+// version.
+type VersionCiphers struct {
+	Version uint16
+	// Name is the name of Version, e.g. "TLS 1.2".
+	Name     string
+	Accepted bool
+	// Ciphers are the accepted suites, in the server's order of preference
+	// when it enforces one.
+	Ciphers []CipherResult
+	// PreferenceKnown is true when the server accepted at least two suites,
+	// so ServerPreference could be tested.
+	PreferenceKnown bool
+	// ServerPreference is true when the server picks a suite by its own
+	// order of preference rather than the client's.
+	ServerPreference bool
+}
+
+// ScanCiphers finds the cipher suites target accepts for each of TLS 1.0 to
+// 1.3 by offering them one at a time, and whether it enforces its own order
+// of preference. target is interpreted as for Read in server mode.
+func ScanCiphers(ctx context.Context, target string, opts ServerOptions) ([]VersionCiphers, error) {
+	addr, err := GetAddress(target, defaultPort)
+	if err != nil {
+		return nil, err
+	}
+	opts = opts.forTarget(target)
+
+	results := make([]VersionCiphers, 0, len(scanVersions))
+	for _, version := range scanVersions {
+		result, err := scanVersionCiphers(ctx, addr, opts, version)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func scanVersionCiphers(ctx context.Context, addr string, opts ServerOptions, version uint16) (VersionCiphers, error) {
+	result := VersionCiphers{Version: version, Name: tls.VersionName(version)}
+
+	// Skip a version the server does not accept at all rather than offer
+	// it every suite in turn.
+	_, err := handshake(ctx, addr, opts, limitTo(version, allCipherSuites()))
+	rejection, err := probeOutcome(err)
+	if err != nil || rejection != nil {
+		return result, err
+	}
+	result.Accepted = true
+
+	var accepted []uint16
+	for _, suite := range cipherSuitesFor(version) {
+		rejection, err := probeOutcome(offerSuite(ctx, addr, opts, version, suite))
+		if err != nil {
+			return result, err
+		}
+		if rejection == nil {
+			accepted = append(accepted, suite)
+		}
+	}
+
+	if len(accepted) >= 2 {
+		ordered, serverPreference, err := preferenceOrder(ctx, addr, opts, version, accepted)
+		rejection, err := probeOutcome(err)
+		if err != nil {
+			return result, err
+		}
+		// A server that chokes on our own ClientHello leaves the order
+		// unknown, which is no reason to throw away what was found.
+		if rejection == nil {
+			result.PreferenceKnown = true
+			result.ServerPreference = serverPreference
+			accepted = ordered
+		}
+	}
+
+	for _, suite := range accepted {
+		result.Ciphers = append(result.Ciphers, NewCipherResult(suite))
+	}
+	return result, nil
+}
+
+// offerSuite attempts a handshake for version offering only suite. crypto/tls
+// offers every TLS 1.3 suite whatever it is configured with, so for TLS 1.3
+// the suite is offered in a ClientHello of our own, taken as far as the
+// ServerHello.
+func offerSuite(ctx context.Context, addr string, opts ServerOptions, version uint16, suite uint16) error {
+	if version == tls.VersionTLS13 {
+		_, err := preferredSuite(ctx, addr, opts, version, []uint16{suite})
+		return err
+	}
+	_, err := handshake(ctx, addr, opts, limitTo(version, []uint16{suite}))
+	return err
+}
+
+// preferenceOrder tells whether the server prefers its own order by offering
+// the first two accepted suites both ways round. If it does, it returns the
+// accepted suites in the server's order, found by offering what is left
+// after taking away each choice in turn.
+func preferenceOrder(ctx context.Context, addr string, opts ServerOptions, version uint16, accepted []uint16) ([]uint16, bool, error) {
+	forward, err := preferredSuite(ctx, addr, opts, version, []uint16{accepted[0], accepted[1]})
+	if err != nil {
+		return nil, false, err
+	}
+	backward, err := preferredSuite(ctx, addr, opts, version, []uint16{accepted[1], accepted[0]})
+	if err != nil {
+		return nil, false, err
+	}
+	if forward != backward {
+		return accepted, false, nil
+	}
+
+	remaining := slices.Clone(accepted)
+	var ordered []uint16
+	for len(remaining) > 1 {
+		chosen, err := preferredSuite(ctx, addr, opts, version, remaining)
+		if err != nil {
+			return nil, false, err
+		}
+		i := slices.Index(remaining, chosen)
+		if i < 0 {
+			return nil, false, &handshakeError{err: fmt.Errorf("server chose %s, which was not offered", tls.CipherSuiteName(chosen))}
+		}
+		ordered = append(ordered, chosen)
+		remaining = slices.Delete(remaining, i, i+1)
+	}
+	return append(ordered, remaining...), true, nil
+}
+
+// preferredSuite returns the suite the server picks when offered suites for
+// version in exactly that order.
+func preferredSuite(ctx context.Context, addr string, opts ServerOptions, version uint16, suites []uint16) (uint16, error) {
+	var chosen uint16
+	_, err := connect(ctx, addr, opts, nil, func(_ context.Context, conn net.Conn, config *tls.Config, _ *Result) error {
+		var err error
+		chosen, err = serverChoice(conn, version, config.ServerName, suites)
+		return err
+	})
+	return chosen, err
+}
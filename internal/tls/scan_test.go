@@ -0,0 +1,67 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbeOutcome(t *testing.T) {
+	refused := errors.New("remote error: tls: protocol version not supported")
+	unreachable := errors.New("connection refused")
+
+	rejection, fatal := probeOutcome(nil)
+	assert.NoError(t, rejection)
+	assert.NoError(t, fatal)
+
+	rejection, fatal = probeOutcome(&handshakeError{err: refused})
+	assert.EqualError(t, rejection, refused.Error())
+	assert.NoError(t, fatal)
+
+	rejection, fatal = probeOutcome(&handshakeError{err: NewErrTimeout(PhaseHandshake, "example.com:443")})
+	assert.ErrorIs(t, rejection, context.DeadlineExceeded)
+	assert.NoError(t, fatal)
+
+	rejection, fatal = probeOutcome(&handshakeError{err: context.Canceled})
+	assert.NoError(t, rejection)
+	assert.ErrorIs(t, fatal, context.Canceled)
+
+	rejection, fatal = probeOutcome(unreachable)
+	assert.NoError(t, rejection)
+	assert.Equal(t, unreachable, fatal)
+}
+
+func TestNewCipherResult(t *testing.T) {
+	tests := []struct {
+		id         uint16
+		grade      CipherGrade
+		weaknesses []string
+	}{
+		{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, GradeStrong, nil},
+		{tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA, GradeWeak, []string{"CBC"}},
+		{tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA, GradeInsecure, []string{"RC4"}},
+		{tls.TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA, GradeInsecure, []string{"CBC", "3DES"}},
+		{tls.TLS_RSA_WITH_AES_256_GCM_SHA384, GradeInsecure, []string{"no PFS"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tls.CipherSuiteName(tt.id), func(t *testing.T) {
+			result := NewCipherResult(tt.id)
+
+			assert.Equal(t, tls.CipherSuiteName(tt.id), result.Name)
+			assert.Equal(t, tt.grade, result.Grade)
+			assert.Equal(t, tt.weaknesses, result.Weaknesses)
+		})
+	}
+}
+
+func TestCipherSuitesFor(t *testing.T) {
+	tls10 := cipherSuitesFor(tls.VersionTLS10)
+
+	assert.Contains(t, tls10, tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA)
+	assert.NotContains(t, tls10, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256)
+	assert.NotContains(t, cipherSuitesFor(tls.VersionTLS12), tls.TLS_AES_128_GCM_SHA256)
+}
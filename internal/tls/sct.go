@@ -0,0 +1,183 @@
+package tls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// oidSCTList is the extension holding the SCTs embedded in a certificate,
+// RFC 6962 section 3.3.
+var oidSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+// SCTSource is where an SCT was found.
+type SCTSource string
+
+const (
+	SCTSourceCertificate SCTSource = "certificate"
+	SCTSourceHandshake   SCTSource = "handshake"
+)
+
+// SCT is a signed certificate timestamp, a Certificate Transparency log's
+// promise to include a certificate, RFC 6962 section 3.2.
+type SCT struct {
+	Source SCTSource
+	// Version is 0 for v1, the only version in use.
+	Version uint8
+	// LogID is the SHA-256 of the log's public key.
+	LogID     [32]byte
+	Timestamp time.Time
+	// HashAlgorithm and SignatureAlgorithm are the TLS HashAlgorithm and
+	// SignatureAlgorithm of the log's signature, RFC 5246 section 7.4.1.4.1.
+	HashAlgorithm      uint8
+	SignatureAlgorithm uint8
+}
+
+// EmbeddedSCTs returns the SCTs embedded in cert, none if it has no SCT list
+// extension.
+func EmbeddedSCTs(cert *x509.Certificate) ([]SCT, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidSCTList) {
+			continue
+		}
+
+		var list []byte
+		if rest, err := asn1.Unmarshal(ext.Value, &list); err != nil || len(rest) > 0 {
+			return nil, fmt.Errorf("invalid SCT list extension")
+		}
+		return parseSCTList(list)
+	}
+	return nil, nil
+}
+
+// HandshakeSCTs returns the SCTs a server sent in the TLS extension.
+func HandshakeSCTs(state *tls.ConnectionState) ([]SCT, error) {
+	var scts []SCT
+	for _, data := range state.SignedCertificateTimestamps {
+		sct, err := parseSCT(data, SCTSourceHandshake)
+		if err != nil {
+			return nil, err
+		}
+		scts = append(scts, sct)
+	}
+	return scts, nil
+}
+
+// parseSCTList parses a SignedCertificateTimestampList, RFC 6962 section
+// 3.3: a length prefixed list of length prefixed SCTs.
+func parseSCTList(data []byte) ([]SCT, error) {
+	list, rest, ok := readVector(data)
+	if !ok || len(rest) > 0 {
+		return nil, fmt.Errorf("invalid SCT list: bad length")
+	}
+
+	var scts []SCT
+	for len(list) > 0 {
+		var entry []byte
+		entry, list, ok = readVector(list)
+		if !ok {
+			return nil, fmt.Errorf("invalid SCT list: bad length")
+		}
+		sct, err := parseSCT(entry, SCTSourceCertificate)
+		if err != nil {
+			return nil, err
+		}
+		scts = append(scts, sct)
+	}
+	return scts, nil
+}
+
+// parseSCT parses a single serialized SCT. The signature itself is not
+// checked, which would need the log's public key.
+func parseSCT(data []byte, source SCTSource) (SCT, error) {
+	// version, log ID, timestamp and the extensions length
+	const fixed = 1 + 32 + 8 + 2
+	if len(data) < fixed {
+		return SCT{}, fmt.Errorf("invalid SCT: truncated")
+	}
+
+	sct := SCT{Source: source, Version: data[0]}
+	if sct.Version != 0 {
+		return SCT{}, fmt.Errorf("invalid SCT: unsupported version %d", sct.Version+1)
+	}
+	copy(sct.LogID[:], data[1:33])
+	sct.Timestamp = time.UnixMilli(int64(binary.BigEndian.Uint64(data[33:41]))).UTC()
+
+	_, rest, ok := readVector(data[41:])
+	if !ok || len(rest) < 2 {
+		return SCT{}, fmt.Errorf("invalid SCT: truncated")
+	}
+	sct.HashAlgorithm = rest[0]
+	sct.SignatureAlgorithm = rest[1]
+	if _, rest, ok = readVector(rest[2:]); !ok || len(rest) > 0 {
+		return SCT{}, fmt.Errorf("invalid SCT: bad signature length")
+	}
+	return sct, nil
+}
+
+// readVector splits a vector with a two byte length prefix from the front
+// of data.
+func readVector(data []byte) (vector, rest []byte, ok bool) {
+	if len(data) < 2 {
+		return nil, nil, false
+	}
+	n := int(binary.BigEndian.Uint16(data))
+	if len(data) < 2+n {
+		return nil, nil, false
+	}
+	return data[2 : 2+n], data[2+n:], true
+}
+
+// LogList maps Certificate Transparency log IDs to the logs' descriptions.
+type LogList map[[32]byte]string
+
+// logListJSON is the part of the v3 log list format, as published at
+// https://www.gstatic.com/ct/log_list/v3/log_list.json, that LoadLogList
+// reads.
+type logListJSON struct {
+	Operators []struct {
+		Logs      []logJSON `json:"logs"`
+		TiledLogs []logJSON `json:"tiled_logs"`
+	} `json:"operators"`
+}
+
+type logJSON struct {
+	Description string `json:"description"`
+	LogID       string `json:"log_id"`
+}
+
+// LoadLogList reads a log list in the v3 JSON format used by Chrome. It
+// returns nil when path is empty.
+func LoadLogList(path string) (LogList, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log list %s: %w", path, err)
+	}
+
+	var parsed logListJSON
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse log list %s: %w", path, err)
+	}
+
+	logs := LogList{}
+	for _, operator := range parsed.Operators {
+		for _, log := range append(operator.Logs, operator.TiledLogs...) {
+			id, err := base64.StdEncoding.DecodeString(log.LogID)
+			if err != nil || len(id) != 32 {
+				return nil, fmt.Errorf("failed to parse log list %s: invalid log_id %q", path, log.LogID)
+			}
+			logs[[32]byte(id)] = log.Description
+		}
+	}
+	return logs, nil
+}
@@ -0,0 +1,141 @@
+package tls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// buildSCT serializes a v1 SCT from logID at timestamp with an ECDSA-SHA256
+// signature.
+func buildSCT(logID byte, timestamp time.Time) []byte {
+	sct := []byte{0}
+	for range 32 {
+		sct = append(sct, logID)
+	}
+	sct = binary.BigEndian.AppendUint64(sct, uint64(timestamp.UnixMilli()))
+	sct = binary.BigEndian.AppendUint16(sct, 0) // no extensions
+	sct = append(sct, 4, 3)                     // SHA-256, ECDSA
+	signature := []byte{0x30, 0x01, 0x02}
+	sct = binary.BigEndian.AppendUint16(sct, uint16(len(signature)))
+	return append(sct, signature...)
+}
+
+func buildSCTList(scts ...[]byte) []byte {
+	var list []byte
+	for _, sct := range scts {
+		list = binary.BigEndian.AppendUint16(list, uint16(len(sct)))
+		list = append(list, sct...)
+	}
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(list))), list...)
+}
+
+func TestEmbeddedSCTs(t *testing.T) {
+	timestamp := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	value, err := asn1.Marshal(buildSCTList(buildSCT(1, timestamp), buildSCT(2, timestamp.Add(time.Second))))
+	assert.NoError(t, err)
+	cert := &x509.Certificate{Extensions: []pkix.Extension{{Id: oidSCTList, Value: value}}}
+
+	scts, err := EmbeddedSCTs(cert)
+
+	assert.NoError(t, err)
+	assert.Len(t, scts, 2)
+	assert.Equal(t, SCTSourceCertificate, scts[0].Source)
+	assert.Equal(t, byte(1), scts[0].LogID[0])
+	assert.Equal(t, timestamp, scts[0].Timestamp)
+	assert.Equal(t, uint8(4), scts[0].HashAlgorithm)
+	assert.Equal(t, uint8(3), scts[0].SignatureAlgorithm)
+	assert.Equal(t, byte(2), scts[1].LogID[31])
+}
+
+func TestEmbeddedSCTsWithoutExtension(t *testing.T) {
+	scts, err := EmbeddedSCTs(&x509.Certificate{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, scts)
+}
+
+func TestHandshakeSCTs(t *testing.T) {
+	timestamp := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	state := &tls.ConnectionState{SignedCertificateTimestamps: [][]byte{buildSCT(7, timestamp)}}
+
+	scts, err := HandshakeSCTs(state)
+
+	assert.NoError(t, err)
+	assert.Len(t, scts, 1)
+	assert.Equal(t, SCTSourceHandshake, scts[0].Source)
+	assert.Equal(t, timestamp, scts[0].Timestamp)
+}
+
+func TestParseSCTInvalid(t *testing.T) {
+	valid := buildSCT(1, time.Now())
+	v2 := append([]byte{1}, valid[1:]...)
+
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"truncated", valid[:20], "invalid SCT: truncated"},
+		{"no signature", valid[:len(valid)-6], "invalid SCT: truncated"},
+		{"short signature", valid[:len(valid)-1], "invalid SCT: bad signature length"},
+		{"unsupported version", v2, "invalid SCT: unsupported version 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSCT(tt.data, SCTSourceHandshake)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseSCTListBadLength(t *testing.T) {
+	list := buildSCTList(buildSCT(1, time.Now()))
+
+	_, err := parseSCTList(list[:len(list)-1])
+
+	assert.EqualError(t, err, "invalid SCT list: bad length")
+}
+
+func TestLoadLogList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log_list.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{
+  "operators": [
+    {
+      "name": "Example",
+      "logs": [{"description": "Example 'Log2025'", "log_id": "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE="}],
+      "tiled_logs": [{"description": "Example 'Tiled2025'", "log_id": "AgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgI="}]
+    }
+  ]
+}`), 0o600))
+
+	logs, err := LoadLogList(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Example 'Log2025'", logs[[32]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}])
+	assert.Equal(t, "Example 'Tiled2025'", logs[[32]byte{2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2}])
+}
+
+func TestLoadLogListInvalidLogID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log_list.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"operators": [{"logs": [{"log_id": "AQID"}]}]}`), 0o600))
+
+	_, err := LoadLogList(path)
+
+	assert.EqualError(t, err, `failed to parse log list `+path+`: invalid log_id "AQID"`)
+}
+
+func TestLoadLogListEmptyPath(t *testing.T) {
+	logs, err := LoadLogList("")
+
+	assert.NoError(t, err)
+	assert.Nil(t, logs)
+}
@@ -0,0 +1,674 @@
+package tls
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// StartTLS is a plaintext protocol that is upgraded to TLS in band before the
+// handshake, rather than speaking TLS from the first byte.
+type StartTLS string
+
+const (
+	StartTLSAuto StartTLS = "auto"
+	StartTLSNone StartTLS = "none"
+	StartTLSSMTP StartTLS = "smtp"
+	StartTLSIMAP StartTLS = "imap"
+	StartTLSPOP3 StartTLS = "pop3"
+	StartTLSFTP  StartTLS = "ftp"
+	// StartTLSPostgres sends the PostgreSQL SSLRequest message.
+	StartTLSPostgres StartTLS = "postgres"
+	// StartTLSMySQL sends a MySQL SSL request packet in reply to the server's
+	// initial handshake.
+	StartTLSMySQL StartTLS = "mysql"
+	// StartTLSLDAP sends the LDAP StartTLS extended operation.
+	StartTLSLDAP StartTLS = "ldap"
+	// StartTLSXMPP negotiates <starttls/> on an XMPP client stream.
+	StartTLSXMPP StartTLS = "xmpp"
+	// StartTLSGeneric sends a line and waits for a reply with a prefix, as
+	// described by a GenericStartTLS.
+	StartTLSGeneric StartTLS = "generic"
+)
+
+// startTLSPorts maps well-known plaintext ports to the protocol StartTLSAuto
+// picks for them.
+var startTLSPorts = map[string]StartTLS{
+	"21":   StartTLSFTP,
+	"25":   StartTLSSMTP,
+	"110":  StartTLSPOP3,
+	"143":  StartTLSIMAP,
+	"587":  StartTLSSMTP,
+	"389":  StartTLSLDAP,
+	"3306": StartTLSMySQL,
+	"5222": StartTLSXMPP,
+	"5432": StartTLSPostgres,
+}
+
+// startTLSNegotiators perform the plaintext upgrade negotiation for each
+// protocol, leaving the connection ready for the TLS handshake.
+var startTLSNegotiators = map[StartTLS]func(conn *bufferedConn, host string) error{
+	StartTLSSMTP:     negotiateSMTP,
+	StartTLSIMAP:     negotiateIMAP,
+	StartTLSPOP3:     negotiatePOP3,
+	StartTLSFTP:      negotiateFTP,
+	StartTLSPostgres: negotiatePostgres,
+	StartTLSMySQL:    negotiateMySQL,
+	StartTLSLDAP:     negotiateLDAP,
+	StartTLSXMPP:     negotiateXMPP,
+}
+
+func ParseStartTLS(s string) (StartTLS, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	protocol := StartTLS(s)
+	if protocol == StartTLSAuto || protocol == StartTLSNone || protocol == StartTLSGeneric {
+		return protocol, nil
+	}
+	if _, ok := startTLSNegotiators[protocol]; ok {
+		return protocol, nil
+	}
+	return "", fmt.Errorf("invalid starttls: %s (must be auto, none, smtp, imap, pop3, ftp, postgres, mysql, ldap, xmpp or generic)", s)
+}
+
+// resolve returns the protocol to use when connecting to port. Auto, or the
+// zero value, picks a protocol from well-known ports and none otherwise.
+func (s StartTLS) resolve(port string) StartTLS {
+	if s != "" && s != StartTLSAuto {
+		return s
+	}
+	if protocol, ok := startTLSPorts[port]; ok {
+		return protocol
+	}
+	return StartTLSNone
+}
+
+type ErrStartTLS struct {
+	Protocol StartTLS
+	Reply    string
+}
+
+func NewErrStartTLS(protocol StartTLS, reply string) *ErrStartTLS {
+	return &ErrStartTLS{
+		Protocol: protocol,
+		Reply:    reply,
+	}
+}
+
+func (e *ErrStartTLS) Error() string {
+	return fmt.Sprintf("%s server refused to start TLS: %s", e.Protocol, e.Reply)
+}
+
+// GenericStartTLS describes a line based upgrade for protocols without a
+// dedicated negotiator: Send is written as a line, then lines are read until
+// one starts with Expect. Any greeting before the reply is skipped.
+type GenericStartTLS struct {
+	Send   string
+	Expect string
+}
+
+func (g GenericStartTLS) negotiate(conn *bufferedConn, _ string) error {
+	if g.Expect == "" {
+		return fmt.Errorf("generic starttls needs a reply prefix to expect")
+	}
+
+	if g.Send != "" {
+		if err := conn.writeLine(g.Send); err != nil {
+			return err
+		}
+	}
+
+	last := ""
+	for {
+		line, err := conn.readLine()
+		if err == io.EOF {
+			return NewErrStartTLS(StartTLSGeneric, fmt.Sprintf("connection closed before a reply starting %q, last line %q", g.Expect, last))
+		}
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(line, g.Expect) {
+			return nil
+		}
+		last = line
+	}
+}
+
+// negotiateStartTLS upgrades conn using protocol, returning the connection to
+// hand to the TLS handshake. generic is only used for StartTLSGeneric.
+func negotiateStartTLS(conn net.Conn, protocol StartTLS, host string, generic GenericStartTLS) (net.Conn, error) {
+	negotiate, ok := startTLSNegotiators[protocol]
+	if protocol == StartTLSGeneric {
+		negotiate, ok = generic.negotiate, true
+	}
+	if !ok {
+		return conn, nil
+	}
+
+	buffered := &bufferedConn{Conn: conn, r: bufio.NewReader(conn)}
+	if err := negotiate(buffered, host); err != nil {
+		return nil, err
+	}
+	return buffered, nil
+}
+
+// bufferedConn reads through a bufio.Reader so that nothing read ahead while
+// parsing plaintext replies is lost to the TLS handshake.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+func (c *bufferedConn) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (c *bufferedConn) writeLine(line string) error {
+	_, err := fmt.Fprintf(c.Conn, "%s\r\n", line)
+	return err
+}
+
+// readReply reads an SMTP or FTP style reply, which is a three digit code
+// followed by "-" on every line but the last and a space on the last.
+func (c *bufferedConn) readReply() (string, []string, error) {
+	var lines []string
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return "", nil, err
+		}
+		lines = append(lines, line)
+		if len(line) < 4 || line[3] != '-' {
+			return line[:min(3, len(line))], lines, nil
+		}
+	}
+}
+
+// expectReply reads a reply and checks it has the wanted code.
+func (c *bufferedConn) expectReply(protocol StartTLS, code string) ([]string, error) {
+	got, lines, err := c.readReply()
+	if err != nil {
+		return nil, err
+	}
+	if got != code {
+		return nil, NewErrStartTLS(protocol, strings.Join(lines, " "))
+	}
+	return lines, nil
+}
+
+func negotiateSMTP(conn *bufferedConn, _ string) error {
+	if _, err := conn.expectReply(StartTLSSMTP, "220"); err != nil {
+		return err
+	}
+
+	if err := conn.writeLine("EHLO localhost"); err != nil {
+		return err
+	}
+	lines, err := conn.expectReply(StartTLSSMTP, "250")
+	if err != nil {
+		return err
+	}
+
+	advertised := false
+	for _, line := range lines {
+		if len(line) > 4 && strings.EqualFold(strings.TrimSpace(line[4:]), "STARTTLS") {
+			advertised = true
+		}
+	}
+	if !advertised {
+		return NewErrStartTLS(StartTLSSMTP, "STARTTLS not advertised in EHLO reply")
+	}
+
+	if err := conn.writeLine("STARTTLS"); err != nil {
+		return err
+	}
+	_, err = conn.expectReply(StartTLSSMTP, "220")
+	return err
+}
+
+func negotiateIMAP(conn *bufferedConn, _ string) error {
+	greeting, err := conn.readLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "* OK") {
+		return NewErrStartTLS(StartTLSIMAP, greeting)
+	}
+
+	if err := conn.writeLine("a001 STARTTLS"); err != nil {
+		return err
+	}
+
+	// Skip any untagged responses until the tagged completion.
+	for {
+		line, err := conn.readLine()
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(line, "a001 ") {
+			continue
+		}
+		if !strings.HasPrefix(line, "a001 OK") {
+			return NewErrStartTLS(StartTLSIMAP, line)
+		}
+		return nil
+	}
+}
+
+func negotiatePOP3(conn *bufferedConn, _ string) error {
+	greeting, err := conn.readLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "+OK") {
+		return NewErrStartTLS(StartTLSPOP3, greeting)
+	}
+
+	if err := conn.writeLine("STLS"); err != nil {
+		return err
+	}
+	reply, err := conn.readLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(reply, "+OK") {
+		return NewErrStartTLS(StartTLSPOP3, reply)
+	}
+	return nil
+}
+
+func negotiateFTP(conn *bufferedConn, _ string) error {
+	if _, err := conn.expectReply(StartTLSFTP, "220"); err != nil {
+		return err
+	}
+
+	if err := conn.writeLine("AUTH TLS"); err != nil {
+		return err
+	}
+	_, err := conn.expectReply(StartTLSFTP, "234")
+	return err
+}
+
+// postgresSSLRequestCode is the protocol version number PostgreSQL reserves
+// to mean "please start TLS".
+const postgresSSLRequestCode = 80877103
+
+func negotiatePostgres(conn *bufferedConn, _ string) error {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], postgresSSLRequestCode)
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	reply, err := conn.r.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	switch reply {
+	case 'S':
+		return nil
+	case 'N':
+		return NewErrStartTLS(StartTLSPostgres, "server does not support SSL")
+	case 'E':
+		return NewErrStartTLS(StartTLSPostgres, "server rejected SSLRequest with an error, it may predate protocol 3.0")
+	default:
+		return NewErrStartTLS(StartTLSPostgres, fmt.Sprintf("unexpected reply %q to SSLRequest", reply))
+	}
+}
+
+// MySQL capability flags, see
+// https://dev.mysql.com/doc/dev/mysql-server/latest/group__group__cs__capabilities__flags.html
+const (
+	mysqlClientLongPassword     = 0x00000001
+	mysqlClientProtocol41       = 0x00000200
+	mysqlClientSSL              = 0x00000800
+	mysqlClientSecureConnection = 0x00008000
+	mysqlErrPacket              = 0xff
+	mysqlCharsetUTF8MB4         = 45
+	mysqlMaxPacketSize          = 1 << 24
+)
+
+func negotiateMySQL(conn *bufferedConn, _ string) error {
+	sequence, payload, err := readMySQLPacket(conn)
+	if err != nil {
+		return err
+	}
+
+	if len(payload) > 0 && payload[0] == mysqlErrPacket {
+		return NewErrStartTLS(StartTLSMySQL, mysqlErrorMessage(payload))
+	}
+
+	capabilities, err := mysqlServerCapabilities(payload)
+	if err != nil {
+		return err
+	}
+	if capabilities&mysqlClientSSL == 0 {
+		return NewErrStartTLS(StartTLSMySQL, "server does not support SSL")
+	}
+
+	request := make([]byte, 4+32)
+	putMySQLHeader(request, 32, sequence+1)
+	binary.LittleEndian.PutUint32(request[4:8], mysqlClientLongPassword|mysqlClientProtocol41|mysqlClientSSL|mysqlClientSecureConnection)
+	binary.LittleEndian.PutUint32(request[8:12], mysqlMaxPacketSize)
+	request[12] = mysqlCharsetUTF8MB4
+	_, err = conn.Write(request)
+	return err
+}
+
+// readMySQLPacket reads one packet, a 3 byte little endian length and a
+// sequence number followed by the payload.
+func readMySQLPacket(conn *bufferedConn) (byte, []byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn.r, header); err != nil {
+		return 0, nil, err
+	}
+
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(conn.r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[3], payload, nil
+}
+
+func putMySQLHeader(b []byte, length int, sequence byte) {
+	b[0] = byte(length)
+	b[1] = byte(length >> 8)
+	b[2] = byte(length >> 16)
+	b[3] = sequence
+}
+
+// mysqlServerCapabilities returns the capability flags advertised in the
+// server's initial handshake packet (protocol version 10).
+func mysqlServerCapabilities(payload []byte) (uint32, error) {
+	if len(payload) == 0 || payload[0] != 10 {
+		return 0, NewErrStartTLS(StartTLSMySQL, "unsupported handshake protocol version")
+	}
+
+	// Skip the protocol version and the NUL terminated server version.
+	end := bytes.IndexByte(payload[1:], 0)
+	if end < 0 {
+		return 0, NewErrStartTLS(StartTLSMySQL, "malformed handshake packet")
+	}
+	pos := 1 + end + 1
+
+	// Then the connection id, the first 8 bytes of auth data and a filler.
+	pos += 4 + 8 + 1
+	if len(payload) < pos+2 {
+		return 0, NewErrStartTLS(StartTLSMySQL, "malformed handshake packet")
+	}
+	capabilities := uint32(binary.LittleEndian.Uint16(payload[pos : pos+2]))
+
+	// The upper half follows the character set and status flags, if present.
+	pos += 2 + 1 + 2
+	if len(payload) >= pos+2 {
+		capabilities |= uint32(binary.LittleEndian.Uint16(payload[pos:pos+2])) << 16
+	}
+	return capabilities, nil
+}
+
+// mysqlErrorMessage extracts the message from an error packet: a 0xff marker,
+// a 2 byte error code, an optional "#" and 5 character SQL state, then text.
+func mysqlErrorMessage(payload []byte) string {
+	if len(payload) < 3 {
+		return "error packet"
+	}
+	code := binary.LittleEndian.Uint16(payload[1:3])
+	message := payload[3:]
+	if len(message) >= 6 && message[0] == '#' {
+		message = message[6:]
+	}
+	return fmt.Sprintf("error %d: %s", code, message)
+}
+
+// ldapStartTLSOID names the LDAP StartTLS extended operation, RFC 4511 4.14.
+const ldapStartTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// LDAP protocol op tags, RFC 4511 4.12.
+const (
+	ldapExtendedRequest  = 23
+	ldapExtendedResponse = 24
+)
+
+// berTagEnumerated is the universal tag of an ENUMERATED, which encoding/asn1
+// has no constant for.
+const berTagEnumerated = 10
+
+// maxLDAPReply caps the size of the StartTLS reply, whose length the server
+// chooses. An ExtendedResponse is never anywhere near it.
+const maxLDAPReply = 64 << 10
+
+// ldapStartTLSRequest returns the LDAPMessage carrying a StartTLS
+// ExtendedRequest with message ID 1.
+func ldapStartTLSRequest() ([]byte, error) {
+	// The request name is a context specific [0] OCTET STRING holding the OID.
+	requestName := append([]byte{0x80, byte(len(ldapStartTLSOID))}, ldapStartTLSOID...)
+
+	return asn1.Marshal(struct {
+		MessageID int
+		Op        asn1.RawValue
+	}{
+		MessageID: 1,
+		Op: asn1.RawValue{
+			Class:      asn1.ClassApplication,
+			Tag:        ldapExtendedRequest,
+			IsCompound: true,
+			Bytes:      requestName,
+		},
+	})
+}
+
+func negotiateLDAP(conn *bufferedConn, _ string) error {
+	request, err := ldapStartTLSRequest()
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	reply, err := readBER(conn)
+	if err != nil {
+		return err
+	}
+
+	// The reply is BER, not DER: servers such as Active Directory send
+	// long form lengths that encoding/asn1 rejects.
+	malformed := NewErrStartTLS(StartTLSLDAP, "malformed reply")
+	message, _, ok := parseBER(reply)
+	if !ok || message.class != asn1.ClassUniversal || message.tag != asn1.TagSequence {
+		return malformed
+	}
+	_, rest, ok := parseBER(message.content) // message ID
+	if !ok {
+		return malformed
+	}
+	op, _, ok := parseBER(rest)
+	if !ok {
+		return malformed
+	}
+	if op.class != asn1.ClassApplication || op.tag != ldapExtendedResponse {
+		return NewErrStartTLS(StartTLSLDAP, fmt.Sprintf("unexpected reply with tag %d", op.tag))
+	}
+
+	code, rest, ok := parseBER(op.content)
+	if !ok || code.tag != berTagEnumerated || len(code.content) == 0 || len(code.content) > 4 {
+		return malformed
+	}
+	resultCode := 0
+	for _, b := range code.content {
+		resultCode = resultCode<<8 | int(b)
+	}
+	if resultCode == 0 {
+		return nil
+	}
+
+	// The result code is followed by the matched DN and a diagnostic message.
+	reason := fmt.Sprintf("result code %d", resultCode)
+	if _, rest, ok := parseBER(rest); ok {
+		if diagnostic, _, ok := parseBER(rest); ok && len(diagnostic.content) > 0 {
+			reason += ": " + string(diagnostic.content)
+		}
+	}
+	return NewErrStartTLS(StartTLSLDAP, reason)
+}
+
+// berElement is a BER encoded element with a definite length.
+type berElement struct {
+	class   int
+	tag     int
+	content []byte
+}
+
+// parseBER splits the first element from data. It accepts any definite
+// length form, as RFC 4511 5.1 allows, but only the low tag numbers LDAP uses.
+func parseBER(data []byte) (element berElement, rest []byte, ok bool) {
+	if len(data) < 2 || data[0]&0x1f == 0x1f {
+		return berElement{}, nil, false
+	}
+	element.class = int(data[0] >> 6)
+	element.tag = int(data[0] & 0x1f)
+
+	length := int(data[1])
+	offset := 2
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 4 || len(data) < offset+n {
+			return berElement{}, nil, false
+		}
+		length = 0
+		for _, b := range data[offset : offset+n] {
+			length = length<<8 | int(b)
+		}
+		offset += n
+	}
+
+	if length > len(data)-offset {
+		return berElement{}, nil, false
+	}
+	element.content = data[offset : offset+length]
+	return element, data[offset+length:], true
+}
+
+// readBER reads one complete BER encoded element, tag, length and content.
+func readBER(conn *bufferedConn) ([]byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn.r, header); err != nil {
+		return nil, err
+	}
+
+	length := int(header[1])
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 4 {
+			return nil, NewErrStartTLS(StartTLSLDAP, "unsupported reply length")
+		}
+		lengthBytes := make([]byte, n)
+		if _, err := io.ReadFull(conn.r, lengthBytes); err != nil {
+			return nil, err
+		}
+		header = append(header, lengthBytes...)
+		length = 0
+		for _, b := range lengthBytes {
+			length = length<<8 | int(b)
+		}
+	}
+	if length > maxLDAPReply {
+		return nil, NewErrStartTLS(StartTLSLDAP, "reply too large")
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(conn.r, content); err != nil {
+		return nil, err
+	}
+	return append(header, content...), nil
+}
+
+const xmppTLSNamespace = "urn:ietf:params:xml:ns:xmpp-tls"
+
+func negotiateXMPP(conn *bufferedConn, host string) error {
+	var header bytes.Buffer
+	header.WriteString("<?xml version='1.0'?><stream:stream to='")
+	if err := xml.EscapeText(&header, []byte(host)); err != nil {
+		return err
+	}
+	header.WriteString("' xmlns='jabber:client' xmlns:stream='http://etherx.jabber.org/streams' version='1.0'>")
+	if _, err := conn.Write(header.Bytes()); err != nil {
+		return err
+	}
+
+	// The decoder reads byte by byte from the bufio.Reader, so it never
+	// consumes any of the TLS handshake that follows.
+	decoder := xml.NewDecoder(conn.r)
+
+	offered, err := readXMPPFeatures(decoder)
+	if err != nil {
+		return err
+	}
+	if !offered {
+		return NewErrStartTLS(StartTLSXMPP, "starttls not offered in stream features")
+	}
+
+	if _, err := fmt.Fprintf(conn, "<starttls xmlns='%s'/>", xmppTLSNamespace); err != nil {
+		return err
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "proceed":
+			return nil
+		case "failure":
+			return NewErrStartTLS(StartTLSXMPP, "server replied with <failure/>")
+		}
+	}
+}
+
+// readXMPPFeatures reads up to the end of the server's <stream:features> and
+// reports whether they include <starttls/>.
+func readXMPPFeatures(decoder *xml.Decoder) (bool, error) {
+	inFeatures := false
+	offered := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "features" {
+				inFeatures = true
+			}
+			if inFeatures && t.Name.Local == "starttls" && t.Name.Space == xmppTLSNamespace {
+				offered = true
+			}
+		case xml.EndElement:
+			if t.Name.Local == "features" {
+				return offered, nil
+			}
+		}
+	}
+}
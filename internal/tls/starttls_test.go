@@ -0,0 +1,114 @@
+package tls
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStartTLS(t *testing.T) {
+	for _, s := range []string{"auto", "none", "smtp", "imap", "pop3", "ftp", "postgres", "mysql", "ldap", "xmpp", "generic"} {
+		protocol, err := ParseStartTLS(s)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StartTLS(s), protocol)
+	}
+}
+
+func TestParseStartTLS_InvalidShouldReturnError(t *testing.T) {
+	protocol, err := ParseStartTLS("gopher")
+
+	assert.EqualError(t, err, "invalid starttls: gopher (must be auto, none, smtp, imap, pop3, ftp, postgres, mysql, ldap, xmpp or generic)")
+	assert.Equal(t, StartTLS(""), protocol)
+}
+
+func TestStartTLSResolve_AutoPicksFromWellKnownPorts(t *testing.T) {
+	assert.Equal(t, StartTLSSMTP, StartTLSAuto.resolve("25"))
+	assert.Equal(t, StartTLSSMTP, StartTLSAuto.resolve("587"))
+	assert.Equal(t, StartTLSIMAP, StartTLSAuto.resolve("143"))
+	assert.Equal(t, StartTLSPOP3, StartTLSAuto.resolve("110"))
+	assert.Equal(t, StartTLSFTP, StartTLSAuto.resolve("21"))
+	assert.Equal(t, StartTLSPostgres, StartTLSAuto.resolve("5432"))
+	assert.Equal(t, StartTLSMySQL, StartTLSAuto.resolve("3306"))
+	assert.Equal(t, StartTLSLDAP, StartTLSAuto.resolve("389"))
+	assert.Equal(t, StartTLSXMPP, StartTLSAuto.resolve("5222"))
+	assert.Equal(t, StartTLSNone, StartTLSAuto.resolve("443"))
+	assert.Equal(t, StartTLSSMTP, StartTLS("").resolve("25"))
+}
+
+func TestStartTLSResolve_ExplicitProtocolWins(t *testing.T) {
+	assert.Equal(t, StartTLSNone, StartTLSNone.resolve("25"))
+	assert.Equal(t, StartTLSIMAP, StartTLSIMAP.resolve("2143"))
+}
+
+func TestMySQLErrorMessage(t *testing.T) {
+	payload := append([]byte{0xff, 0x15, 0x04}, "#28000Access denied"...)
+
+	assert.Equal(t, "error 1045: Access denied", mysqlErrorMessage(payload))
+}
+
+func TestLDAPStartTLSRequest(t *testing.T) {
+	request, err := ldapStartTLSRequest()
+
+	assert.NoError(t, err)
+	expected := append([]byte{0x30, 0x1d, 0x02, 0x01, 0x01, 0x77, 0x18, 0x80, 0x16}, "1.3.6.1.4.1.1466.20037"...)
+	assert.Equal(t, expected, request)
+}
+
+// berLong encodes an element with tag and a four byte long form length, as
+// Active Directory does.
+func berLong(tag byte, content ...byte) []byte {
+	element := []byte{tag, 0x84}
+	element = binary.BigEndian.AppendUint32(element, uint32(len(content)))
+	return append(element, content...)
+}
+
+// negotiateLDAPWithReply runs negotiateLDAP against a server that answers
+// the StartTLS request with reply.
+func negotiateLDAPWithReply(t *testing.T, reply []byte) error {
+	client, server := net.Pipe()
+	t.Cleanup(func() { _ = client.Close() })
+
+	go func() {
+		defer func() { _ = server.Close() }()
+		request, _ := ldapStartTLSRequest()
+		if _, err := io.ReadFull(server, make([]byte, len(request))); err != nil {
+			return
+		}
+		_, _ = server.Write(reply)
+	}()
+
+	return negotiateLDAP(&bufferedConn{Conn: client, r: bufio.NewReader(client)}, "")
+}
+
+func TestNegotiateLDAPLongFormLengths(t *testing.T) {
+	response := berLong(0x78, 0x0a, 0x01, 0x00, 0x04, 0x00, 0x04, 0x00)
+	reply := berLong(0x30, append([]byte{0x02, 0x01, 0x01}, response...)...)
+
+	assert.NoError(t, negotiateLDAPWithReply(t, reply))
+}
+
+func TestNegotiateLDAPLongFormRefusal(t *testing.T) {
+	diagnostic := berLong(0x04, []byte("unsupported")...)
+	content := append([]byte{0x0a, 0x01, 0x02}, berLong(0x04)...)
+	response := berLong(0x78, append(content, diagnostic...)...)
+	reply := berLong(0x30, append([]byte{0x02, 0x01, 0x01}, response...)...)
+
+	assert.EqualError(t, negotiateLDAPWithReply(t, reply), "ldap server refused to start TLS: result code 2: unsupported")
+}
+
+func TestNegotiateLDAPReplyTooLarge(t *testing.T) {
+	reply := []byte{0x30, 0x84, 0xff, 0xff, 0xff, 0xff}
+
+	assert.Equal(t, NewErrStartTLS(StartTLSLDAP, "reply too large"), negotiateLDAPWithReply(t, reply))
+}
+
+func TestParseBERRejectsTruncatedContent(t *testing.T) {
+	_, _, ok := parseBER([]byte{0x30, 0x84, 0x00, 0x00, 0x00, 0x05, 0x02, 0x01})
+
+	assert.False(t, ok)
+}
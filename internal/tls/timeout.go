@@ -0,0 +1,87 @@
+package tls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// aLongTimeAgo is a deadline in the past, which fails pending I/O at once.
+var aLongTimeAgo = time.Unix(1, 0)
+
+// Phase is a step of connecting to a server that can time out.
+type Phase string
+
+const (
+	PhaseDNS       Phase = "dns"
+	PhaseConnect   Phase = "connect"
+	PhaseHandshake Phase = "handshake"
+)
+
+// ErrTimeout is returned when reading from a server takes longer than its
+// timeout. It unwraps to context.DeadlineExceeded.
+type ErrTimeout struct {
+	Phase  Phase
+	Target string
+}
+
+func NewErrTimeout(phase Phase, target string) *ErrTimeout {
+	return &ErrTimeout{Phase: phase, Target: target}
+}
+
+func (e *ErrTimeout) Error() string {
+	switch e.Phase {
+	case PhaseDNS:
+		return fmt.Sprintf("DNS lookup of %s timed out", e.Target)
+	case PhaseConnect:
+		return fmt.Sprintf("TCP connect to %s timed out", e.Target)
+	default:
+		return fmt.Sprintf("TLS handshake with %s timed out", e.Target)
+	}
+}
+
+func (e *ErrTimeout) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// phaseError explains err, which happened during phase, in terms of ctx: a
+// cancelled ctx returns context.Canceled and an expired ctx or a network
+// timeout returns an ErrTimeout. Any other error is returned unchanged.
+func phaseError(ctx context.Context, phase Phase, target string, err error) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return ctx.Err()
+	}
+
+	var netErr net.Error
+	if ctx.Err() != nil || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return NewErrTimeout(phase, target)
+	}
+	return err
+}
+
+// dialTCP connects to addr, telling a DNS lookup that timed out apart from a
+// TCP connect that did.
+func dialTCP(ctx context.Context, addr string) (net.Conn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err == nil {
+		return conn, nil
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return nil, phaseError(ctx, PhaseDNS, dnsErr.Name, err)
+	}
+	return nil, phaseError(ctx, PhaseConnect, addr, err)
+}
+
+// unblockOnDone makes blocked reads and writes on conn fail as soon as ctx is
+// done, for protocol exchanges that take no context themselves. Calling the
+// returned function stops watching ctx.
+func unblockOnDone(ctx context.Context, conn net.Conn) func() bool {
+	return context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(aLongTimeAgo)
+	})
+}
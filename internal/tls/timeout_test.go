@@ -0,0 +1,77 @@
+package tls
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// silentListener never accepts, so connections complete in the kernel's
+// backlog and then hear nothing, like a server that hangs mid-handshake.
+func silentListener(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	return listener.Addr().String()
+}
+
+func TestErrTimeout_Error(t *testing.T) {
+	assert.EqualError(t, NewErrTimeout(PhaseDNS, "example.com"), "DNS lookup of example.com timed out")
+	assert.EqualError(t, NewErrTimeout(PhaseConnect, "10.0.0.1:443"), "TCP connect to 10.0.0.1:443 timed out")
+	assert.EqualError(t, NewErrTimeout(PhaseHandshake, "example.com:443"), "TLS handshake with example.com:443 timed out")
+}
+
+func TestErrTimeout_IsDeadlineExceeded(t *testing.T) {
+	assert.ErrorIs(t, NewErrTimeout(PhaseConnect, "10.0.0.1:443"), context.DeadlineExceeded)
+}
+
+func TestPhaseError(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithDeadline(context.Background(), time.Unix(1, 0))
+	defer cancel()
+	other := errors.New("connection refused")
+
+	assert.ErrorIs(t, phaseError(cancelled, PhaseConnect, "example.com:443", other), context.Canceled)
+	assert.Equal(t, NewErrTimeout(PhaseConnect, "example.com:443"), phaseError(expired, PhaseConnect, "example.com:443", other))
+	assert.Equal(t, NewErrTimeout(PhaseDNS, "example.com"),
+		phaseError(context.Background(), PhaseDNS, "example.com", &net.DNSError{Name: "example.com", IsTimeout: true}))
+	assert.Equal(t, other, phaseError(context.Background(), PhaseConnect, "example.com:443", other))
+}
+
+func TestReadServer_HandshakeTimeout(t *testing.T) {
+	addr := silentListener(t)
+
+	_, err := ReadServer(context.Background(), addr, ServerOptions{StartTLS: StartTLSNone, HandshakeTimeout: 100 * time.Millisecond})
+
+	var timeoutErr *ErrTimeout
+	assert.ErrorAs(t, err, &timeoutErr)
+	assert.Equal(t, PhaseHandshake, timeoutErr.Phase)
+}
+
+func TestReadServer_StartTLSTimeout(t *testing.T) {
+	addr := silentListener(t)
+
+	_, err := ReadServer(context.Background(), addr, ServerOptions{StartTLS: StartTLSSMTP, Timeout: 100 * time.Millisecond})
+
+	assert.EqualError(t, err, "TLS handshake with "+addr+" timed out")
+}
+
+func TestReadServer_CancelledContext(t *testing.T) {
+	addr := silentListener(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	_, err := ReadServer(ctx, addr, ServerOptions{StartTLS: StartTLSNone})
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
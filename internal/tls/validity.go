@@ -0,0 +1,62 @@
+package tls
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+)
+
+type ValidityStatus string
+
+const (
+	StatusValid       ValidityStatus = "valid"
+	StatusExpired     ValidityStatus = "expired"
+	StatusNotYetValid ValidityStatus = "not_yet_valid"
+)
+
+// Validity describes where a point in time falls within a certificate's
+// validity period.
+type Validity struct {
+	Status ValidityStatus
+	// Remaining is the time left until NotAfter, negative once expired.
+	Remaining time.Duration
+	// StartsIn is the time left until NotBefore, zero once the certificate
+	// has become valid.
+	StartsIn time.Duration
+}
+
+// CheckValidity returns the validity of cert at now.
+func CheckValidity(cert *x509.Certificate, now time.Time) Validity {
+	v := Validity{
+		Status:    StatusValid,
+		Remaining: cert.NotAfter.Sub(now),
+	}
+
+	switch {
+	case now.Before(cert.NotBefore):
+		v.Status = StatusNotYetValid
+		v.StartsIn = cert.NotBefore.Sub(now)
+	case v.Remaining <= 0:
+		v.Status = StatusExpired
+	}
+
+	return v
+}
+
+// HumanDuration formats d as whole days and hours, e.g. "3 days 5 hours", or
+// just hours when d is less than a day. Negative durations are formatted by
+// their magnitude.
+func HumanDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+
+	totalHours := int(d.Hours())
+	days := totalHours / 24
+	hours := totalHours % 24
+
+	if days < 1 {
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return fmt.Sprintf("%d days %d hours", days, hours)
+}
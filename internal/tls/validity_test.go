@@ -0,0 +1,42 @@
+package tls
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kevholditch/tls/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestCheckValidity_Valid(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithValidity(now.Add(-time.Hour), now.Add(50*time.Hour)).BuildCert()
+
+	assert.Equal(t, Validity{Status: StatusValid, Remaining: 50 * time.Hour}, CheckValidity(cert, now))
+}
+
+func TestCheckValidity_Expired(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithValidity(now.Add(-100*time.Hour), now.Add(-77*time.Hour)).BuildCert()
+
+	assert.Equal(t, Validity{Status: StatusExpired, Remaining: -77 * time.Hour}, CheckValidity(cert, now))
+}
+
+func TestCheckValidity_ExpiresExactlyNow(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithValidity(now.Add(-time.Hour), now).BuildCert()
+
+	assert.Equal(t, StatusExpired, CheckValidity(cert, now).Status)
+}
+
+func TestCheckValidity_NotYetValid(t *testing.T) {
+	cert := testutil.NewCertBuilder().WithValidity(now.Add(48*time.Hour), now.Add(100*time.Hour)).BuildCert()
+
+	assert.Equal(t, Validity{Status: StatusNotYetValid, Remaining: 100 * time.Hour, StartsIn: 48 * time.Hour}, CheckValidity(cert, now))
+}
+
+func TestHumanDuration(t *testing.T) {
+	assert.Equal(t, "0 hours", HumanDuration(0))
+	assert.Equal(t, "23 hours", HumanDuration(23*time.Hour+59*time.Minute))
+	assert.Equal(t, "3 days 5 hours", HumanDuration(77*time.Hour))
+	assert.Equal(t, "3 days 5 hours", HumanDuration(-77*time.Hour))
+}
@@ -0,0 +1,94 @@
+package tls
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LoadRoots returns the pool of roots to verify against. When caFile and
+// caDir are both empty that is the system pool, otherwise it holds only the
+// certificates in caFile and the files in caDir.
+func LoadRoots(caFile, caDir string) (*x509.CertPool, error) {
+	if caFile == "" && caDir == "" {
+		return x509.SystemCertPool()
+	}
+
+	roots := x509.NewCertPool()
+
+	if caFile != "" {
+		result, err := ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file %s: %w", caFile, err)
+		}
+		for _, cert := range result.Certificates {
+			roots.AddCert(cert)
+		}
+	}
+
+	if caDir != "" {
+		if err := addRootsFromDir(roots, caDir); err != nil {
+			return nil, err
+		}
+	}
+
+	return roots, nil
+}
+
+// addRootsFromDir adds the certificates in every file in dir to roots. Files
+// that don't hold certificates, such as CRLs or READMEs, are ignored.
+func addRootsFromDir(roots *x509.CertPool, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read CA directory %s: %w", dir, err)
+	}
+
+	found := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		result, err := ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		for _, cert := range result.Certificates {
+			roots.AddCert(cert)
+			found++
+		}
+	}
+
+	if found == 0 {
+		return fmt.Errorf("no certificates found in CA directory %s", dir)
+	}
+	return nil
+}
+
+// Verify checks that certs, a leaf followed by any intermediates, chain up to
+// one of roots at now. When dnsName is not empty the leaf must also be valid
+// for it. The verified chain is returned, ending in the trusted root.
+func Verify(certs []*x509.Certificate, roots *x509.CertPool, dnsName string, now time.Time) ([]*x509.Certificate, error) {
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates to verify")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	chains, err := certs[0].Verify(x509.VerifyOptions{
+		DNSName:       dnsName,
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chains[0], nil
+}